@@ -27,6 +27,7 @@ import (
 	"maps"
 	"math"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"slices"
@@ -45,6 +46,7 @@ import (
 	"chainguard.dev/apko/pkg/tarfs"
 	"github.com/chainguard-dev/clog"
 	purl "github.com/package-url/packageurl-go"
+	"github.com/spdx/tools-golang/spdx/v2/common"
 	"github.com/yookoala/realpath"
 	"github.com/zealic/xignore"
 	"go.opentelemetry.io/otel"
@@ -56,7 +58,9 @@ import (
 	"chainguard.dev/melange/pkg/index"
 	"chainguard.dev/melange/pkg/license"
 	"chainguard.dev/melange/pkg/linter"
+	"chainguard.dev/melange/pkg/linter/linters"
 	"chainguard.dev/melange/pkg/sbom"
+	"chainguard.dev/melange/pkg/sca"
 )
 
 const melangeOutputDirName = "melange-out"
@@ -120,6 +124,11 @@ type Build struct {
 	ExtraRepos            []string
 	ExtraPackages         []string
 	DependencyLog         string
+	ExplainDependencies   bool
+	AllowFileConflicts    bool
+	LintBaseline          string
+	LintSARIF             bool
+	LintAgainst           string
 	BinShOverlay          string
 	CreateBuildLog        bool
 	PersistLintResults    bool
@@ -162,6 +171,15 @@ type Build struct {
 	// This is only applicable when there's a build context.  It
 	// is filled by buildGuest.
 	PkgResolver *apk.PkgResolver
+
+	// The remote `uses` pipelines the build depends on. This is filled by
+	// Compile.
+	RemotePipelines []RemotePipeline
+
+	// Opt-in checks of pipeline scripts for likely mistakes. The findings are
+	// filled by Compile.
+	CheckScripts   bool
+	ScriptFindings []ScriptFinding
 }
 
 func New(ctx context.Context, opts ...Option) (*Build, error) {
@@ -583,8 +601,100 @@ type linterTarget struct {
 	disabled []string // checks that are downgraded from required -> warn
 }
 
+// linters returns the required and warning linters of a package, given the
+// checks it disables.
+func (b *Build) linters(disabled []string) (require, warn []string) {
+	// Downgrade disabled checks from required to warn
+	require = slices.DeleteFunc(slices.Clone(b.LintRequire), func(s string) bool {
+		return slices.Contains(disabled, s)
+	})
+	warn = slices.CompactFunc(append(slices.Clone(b.LintWarn), disabled...), func(a, b string) bool {
+		return a == b
+	})
+	return require, warn
+}
+
+// lintOutDir returns where lint results are persisted.
+func (b *Build) lintOutDir() string {
+	// Conditionally persist lint results based on flag
+	if b.PersistLintResults {
+		return b.OutDir
+	}
+	return ""
+}
+
+// lintSARIFPath returns where the SARIF log of the lint findings is written:
+// {OutDir}/{arch}/lint-{packagename}-{version}-r{epoch}.sarif
+func (b *Build) lintSARIFPath() string {
+	filename := fmt.Sprintf("lint-%s-%s-r%d.sarif", b.Configuration.Package.Name, b.Configuration.Package.Version, b.Configuration.Package.Epoch)
+	return filepath.Join(b.OutDir, b.Arch.ToAPK(), filename)
+}
+
+// lintSiblings returns the workspace filesystems of the packages built
+// alongside pkgName.
+func (b *Build) lintSiblings(pkgName string) (map[string]fs.FS, error) {
+	siblings := map[string]fs.FS{}
+	for name := range b.Configuration.AllPackageNames() {
+		if name == pkgName {
+			continue
+		}
+		sibling, err := apkofs.Sub(b.WorkspaceDirFS, filepath.Join(melangeOutputDirName, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to return filesystem for workspace subtree: %w", err)
+		}
+		siblings[name] = sibling
+	}
+	return siblings, nil
+}
+
+// lintBuildPaths returns the paths on the host which packages shouldn't
+// contain.
+func (b *Build) lintBuildPaths() []string {
+	var paths []string
+	for _, dir := range []string{b.WorkspaceDir, b.CacheDir} {
+		if dir == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(dir); err == nil {
+			paths = append(paths, abs)
+		}
+	}
+	return paths
+}
+
 func (b *Build) BuildPackage(ctx context.Context) error {
 	log := clog.FromContext(ctx)
+
+	// Only the findings the lint baseline doesn't accept fail the build
+	var baseline *linter.Baseline
+	if b.LintBaseline != "" {
+		var err error
+		if baseline, err = linter.LoadBaseline(b.LintBaseline); err != nil {
+			return err
+		}
+		ctx = linter.WithBaseline(ctx, baseline)
+	}
+	if b.LintSARIF {
+		sarif := linter.NewSARIFReport()
+		ctx = linter.WithSARIF(ctx, sarif)
+		// The findings which fail the build are the most important to keep.
+		defer func() {
+			path := b.lintSARIFPath()
+			if err := sarif.Write(path); err != nil {
+				log.Warnf("failed to write SARIF lint results: %v", err)
+				return
+			}
+			log.Infof("wrote SARIF lint results to %s", path)
+		}()
+	}
+	if b.LintAgainst != "" {
+		prev, err := linter.PreviousFromRepository(ctx, b.LintAgainst, b.Arch.ToAPK())
+		if err != nil {
+			return err
+		}
+		defer prev.Close()
+		ctx = linter.WithPreviousPackages(ctx, prev)
+	}
 	ctx, span := otel.Tracer("melange").Start(ctx, "BuildPackage")
 	defer span.End()
 
@@ -649,6 +759,10 @@ func (b *Build) BuildPackage(ctx context.Context) error {
 		return fmt.Errorf("compiling %s: %w", b.ConfigFile, err)
 	}
 
+	for _, f := range b.ScriptFindings {
+		log.Warnf("%s", f)
+	}
+
 	// Filter out any subpackages with false If conditions.
 	b.Configuration.Subpackages = slices.DeleteFunc(b.Configuration.Subpackages, func(sp config.Subpackage) bool {
 		result, err := shouldRun(sp.If)
@@ -667,6 +781,14 @@ func (b *Build) BuildPackage(ctx context.Context) error {
 		return fmt.Errorf("adding SBOM package for build config file: %w", err)
 	}
 
+	for _, rp := range b.RemotePipelines {
+		p, err := rp.SBOMPackage(namespace)
+		if err != nil {
+			return fmt.Errorf("creating SBOM package for remote pipeline %q: %w", rp.Uses, err)
+		}
+		b.SBOMGroup.AddBuildInputPackage(p)
+	}
+
 	pr := &pipelineRunner{
 		interactive: b.Interactive,
 		debug:       b.Debug,
@@ -850,6 +972,10 @@ func (b *Build) BuildPackage(ctx context.Context) error {
 	}
 	log.Infof("retrieved and wrote post-build workspace to: %s", b.WorkspaceDir)
 
+	if err := b.checkFileConflicts(ctx); err != nil {
+		return err
+	}
+
 	// perform package linting
 	for _, lt := range linterQueue {
 		log.Infof("running package linters for %s", lt.pkgName)
@@ -860,19 +986,16 @@ func (b *Build) BuildPackage(ctx context.Context) error {
 			return fmt.Errorf("failed to return filesystem for workspace subtree: %w", err)
 		}
 
-		// Downgrade disabled checks from required to warn
-		require := slices.DeleteFunc(b.LintRequire, func(s string) bool {
-			return slices.Contains(lt.disabled, s)
-		})
-		warn := slices.CompactFunc(append(b.LintWarn, lt.disabled...), func(a, b string) bool {
-			return a == b
-		})
-
-		// Conditionally persist lint results based on flag
-		outDir := ""
-		if b.PersistLintResults {
-			outDir = b.OutDir
+		siblings, err := b.lintSiblings(lt.pkgName)
+		if err != nil {
+			return err
 		}
+		ctx := linters.WithSiblings(ctx, siblings)
+		ctx = linters.WithBuildPaths(ctx, b.lintBuildPaths())
+		ctx = linter.WithPackageDir(ctx, filepath.Join(b.WorkspaceDir, melangeOutputDirName, lt.pkgName))
+
+		require, warn := b.linters(lt.disabled)
+		outDir := b.lintOutDir()
 
 		if err := linter.LintBuild(ctx, b.Configuration, lt.pkgName, require, warn, fsys, outDir, b.Arch.ToAPK()); err != nil {
 			return fmt.Errorf("unable to lint package %s: %w", lt.pkgName, err)
@@ -890,6 +1013,10 @@ func (b *Build) BuildPackage(ctx context.Context) error {
 	}
 	b.SBOMGroup.SetLicensingInfos(li)
 
+	if err := b.addSBOMComponents(ctx, namespace); err != nil {
+		return fmt.Errorf("adding SBOM components: %w", err)
+	}
+
 	// Convert the SBOMs we've been working on to their SPDX representation, and
 	// write them to disk. We'll handle any subpackages first, and then the main
 	// package, but the order doesn't really matter.
@@ -921,6 +1048,10 @@ func (b *Build) BuildPackage(ctx context.Context) error {
 		}
 	}
 
+	if baseline != nil {
+		baseline.ReportStale(ctx)
+	}
+
 	// clean build environment
 	log.Debugf("cleaning workspacedir")
 	cleanEnv := map[string]string{}
@@ -1000,18 +1131,104 @@ func (b *Build) addSBOMPackageForBuildConfigFile() error {
 		return fmt.Errorf("getting PURL for build config: %w", err)
 	}
 
-	b.SBOMGroup.AddBuildConfigurationPackage(&sbom.Package{
+	configPkg := &sbom.Package{
 		Name:            b.ConfigFile,
 		Version:         b.ConfigFileRepositoryCommit,
 		LicenseDeclared: b.ConfigFileLicense,
 		Namespace:       b.Namespace,
 		Arch:            "", // This field doesn't make sense in this context
 		PURL:            buildConfigPURL,
-	})
+	}
+	b.SBOMGroup.AddBuildConfigurationPackage(configPkg)
+
+	// Record the fragments included by the build config alongside it.
+	for _, inc := range b.Configuration.Includes() {
+		name := filepath.Join(filepath.Dir(b.ConfigFile), filepath.FromSlash(inc.Path))
+
+		incPURL := *buildConfigPURL
+		incPURL.Subpath = filepath.ToSlash(name)
+
+		b.SBOMGroup.AddBuildConfigurationInclude(configPkg, &sbom.Package{
+			Name:            name,
+			Version:         b.ConfigFileRepositoryCommit,
+			LicenseDeclared: b.ConfigFileLicense,
+			Namespace:       b.Namespace,
+			Checksums:       map[string]string{"SHA256": inc.SHA256},
+			PURL:            &incPURL,
+		})
+	}
+
+	return nil
+}
+
+// addSBOMComponents adds the third-party components built into the files of
+// each package, such as the modules of Go binaries and the crates of Rust
+// binaries, to its SBOM.
+func (b *Build) addSBOMComponents(ctx context.Context, supplier string) error {
+	names := []string{b.Configuration.Package.Name}
+	for _, sp := range b.Configuration.Subpackages {
+		names = append(names, sp.Name)
+	}
+
+	for _, name := range names {
+		rlFS, err := apkofs.Sub(b.WorkspaceDirFS, filepath.Join(melangeOutputDirName, name))
+		if err != nil {
+			return fmt.Errorf("package build subFS: %w", err)
+		}
+		fsys, ok := rlFS.(sca.SCAFS)
+		if !ok {
+			return fmt.Errorf("SCAFS not implemented")
+		}
+
+		components, err := sca.Components(ctx, fsys)
+		if err != nil {
+			return fmt.Errorf("finding components of %s: %w", name, err)
+		}
+
+		doc := b.SBOMGroup.Document(name)
+		seen := map[string]bool{}
+		for _, c := range components {
+			p := componentSBOMPackage(c, supplier)
+			if seen[p.ID()] {
+				continue
+			}
+			seen[p.ID()] = true
+
+			doc.AddPackage(p)
+			doc.AddRelationship(doc.Describes, p, common.TypeRelationshipContains)
+		}
+	}
 
 	return nil
 }
 
+// componentSBOMPackage returns an SBOM package describing a component built
+// into a file of an APK package.
+func componentSBOMPackage(c sca.Component, supplier string) *sbom.Package {
+	namespace, name := path.Split(c.Name)
+
+	info := []string{"found in " + c.Path}
+	if c.Sum != "" {
+		info = append(info, "sum "+c.Sum)
+	}
+	info = append(info, c.Notes...)
+
+	return &sbom.Package{
+		IDComponents:    []string{"component", c.Type, c.Name, c.Version},
+		Name:            c.Name,
+		Version:         c.Version,
+		LicenseDeclared: spdx.NOASSERTION,
+		Namespace:       supplier,
+		PURL: &purl.PackageURL{
+			Type:      c.Type,
+			Namespace: strings.TrimSuffix(namespace, "/"),
+			Name:      name,
+			Version:   c.Version,
+		},
+		SourceInfo: strings.Join(info, "; "),
+	}
+}
+
 func getPathForPackageSBOM(sbomDirPath, pkgName, pkgVersion string) string {
 	return filepath.Join(
 		sbomDirPath,
@@ -22,7 +22,9 @@ import (
 	"time"
 
 	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/sca"
 
+	apkofs "chainguard.dev/apko/pkg/apk/fs"
 	apko_types "chainguard.dev/apko/pkg/build/types"
 	"github.com/chainguard-dev/clog/slogtest"
 	"github.com/google/go-cmp/cmp"
@@ -380,3 +382,64 @@ func TestSourceDateEpoch(t *testing.T) {
 		})
 	}
 }
+
+func TestComponentSBOMPackage(t *testing.T) {
+	p := componentSBOMPackage(sca.Component{
+		Type:    "golang",
+		Name:    "github.com/example/foo",
+		Version: "v1.2.3",
+		Sum:     "h1:foo=",
+		Path:    "usr/bin/foo",
+		Notes:   []string{"github.com/example/foo v1.2.0 replaced by github.com/example/foo v1.2.3"},
+	}, "wolfi")
+
+	require.Equal(t, "pkg:golang/github.com/example/foo@v1.2.3", p.PURL.ToString())
+	require.Equal(t, "SPDXRef-Package-component-golang-github.com-example-foo-v1.2.3", p.ID())
+	require.Equal(t, "found in usr/bin/foo; sum h1:foo=; github.com/example/foo v1.2.0 replaced by github.com/example/foo v1.2.3", p.SourceInfo)
+}
+
+func TestFindFileConflicts(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	dir := t.TempDir()
+	for _, path := range []string{
+		"foo/usr/bin/foo",
+		"foo/usr/bin/shared",
+		"foo/usr/lib/libfoo.so.1",
+		"foo-compat/usr/bin/shared",
+		"foo-compat/usr/local/bin/foo",
+		"foo-libs/usr/lib/libfoo.so.1",
+		"foo-bin/usr/bin/foo",
+	} {
+		path = filepath.Join(dir, melangeOutputDirName, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("foo"), 0o755))
+	}
+
+	cfg := &config.Configuration{
+		Package: config.Package{Name: "foo"},
+		Subpackages: []config.Subpackage{{
+			Name: "foo-compat",
+		}, {
+			// Replacing foo allows foo-libs to install the same libraries.
+			Name:         "foo-libs",
+			Dependencies: config.Dependencies{Replaces: []string{"foo=1.0-r0"}},
+		}, {
+			Name: "foo-bin",
+		}},
+	}
+
+	got, err := findFileConflicts(ctx, apkofs.DirFS(ctx, dir), cfg)
+	require.NoError(t, err)
+
+	want := []fileConflict{{
+		Path:     "usr/bin/foo",
+		Packages: []string{"foo", "foo-bin"},
+	}, {
+		Path:     "usr/bin/shared",
+		Packages: []string{"foo", "foo-compat"},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("findFileConflicts(): (-want, +got):\n%s", diff)
+	}
+}
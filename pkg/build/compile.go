@@ -48,6 +48,7 @@ func (t *Test) Compile(ctx context.Context) error {
 
 	ignore := &Compiled{
 		PipelineDirs: t.PipelineDirs,
+		CacheDir:     t.CacheDir,
 	}
 
 	// We want to evaluate this but not accumulate its deps.
@@ -75,6 +76,7 @@ func (t *Test) Compile(ctx context.Context) error {
 
 		test := &Compiled{
 			PipelineDirs: t.PipelineDirs,
+			CacheDir:     t.CacheDir,
 		}
 
 		te := &cfg.Subpackages[i].Test.Environment.Contents
@@ -96,6 +98,7 @@ func (t *Test) Compile(ctx context.Context) error {
 	if cfg.Test != nil {
 		test := &Compiled{
 			PipelineDirs: t.PipelineDirs,
+			CacheDir:     t.CacheDir,
 		}
 
 		te := &t.Configuration.Test.Environment.Contents
@@ -131,12 +134,16 @@ func (b *Build) Compile(ctx context.Context) error {
 
 	c := &Compiled{
 		PipelineDirs: b.PipelineDirs,
+		CacheDir:     b.CacheDir,
+		CheckScripts: b.CheckScripts,
 	}
 
 	if err := c.CompilePipelines(ctx, sm, cfg.Pipeline); err != nil {
 		return fmt.Errorf("compiling %q pipelines: %w", cfg.Package.Name, err)
 	}
 
+	var remotes []RemotePipeline
+	var findings []ScriptFinding
 	for i, sp := range cfg.Subpackages {
 		sm := sm.Subpackage(&sp)
 
@@ -157,10 +164,14 @@ func (b *Build) Compile(ctx context.Context) error {
 
 		tc := &Compiled{
 			PipelineDirs: b.PipelineDirs,
+			CacheDir:     b.CacheDir,
+			CheckScripts: b.CheckScripts,
 		}
 		if err := tc.CompilePipelines(ctx, sm, sp.Test.Pipeline); err != nil {
 			return fmt.Errorf("compiling subpackage %q tests: %w", sp.Name, err)
 		}
+		remotes = append(remotes, tc.Remotes...)
+		findings = append(findings, tc.ScriptFindings...)
 
 		te := &cfg.Subpackages[i].Test.Environment.Contents
 
@@ -177,9 +188,14 @@ func (b *Build) Compile(ctx context.Context) error {
 	ic := &b.Configuration.Environment.Contents
 	ic.Packages = append(ic.Packages, c.Needs...)
 
+	remotes = append(remotes, c.Remotes...)
+	findings = append(findings, c.ScriptFindings...)
+
 	if cfg.Test != nil {
 		tc := &Compiled{
 			PipelineDirs: b.PipelineDirs,
+			CacheDir:     b.CacheDir,
+			CheckScripts: b.CheckScripts,
 		}
 
 		if err := tc.CompilePipelines(ctx, sm, cfg.Test.Pipeline); err != nil {
@@ -194,6 +210,19 @@ func (b *Build) Compile(ctx context.Context) error {
 
 		// Sort and remove duplicates.
 		te.Packages = slices.Compact(slices.Sorted(slices.Values(te.Packages)))
+
+		remotes = append(remotes, tc.Remotes...)
+		findings = append(findings, tc.ScriptFindings...)
+	}
+
+	b.ScriptFindings = findings
+
+	// Record every remote pipeline we loaded as an input to the build.
+	b.RemotePipelines = nil
+	for _, rp := range remotes {
+		if !slices.ContainsFunc(b.RemotePipelines, func(r RemotePipeline) bool { return r.Uses == rp.Uses }) {
+			b.RemotePipelines = append(b.RemotePipelines, rp)
+		}
 	}
 
 	return nil
@@ -202,12 +231,25 @@ func (b *Build) Compile(ctx context.Context) error {
 type Compiled struct {
 	PipelineDirs []string
 	Needs        []string
+
+	// The directory remote `uses` pipelines are fetched into.
+	CacheDir string
+	// The remote pipelines that were loaded while compiling.
+	Remotes []RemotePipeline
+
+	// Whether to look for likely mistakes in pipeline scripts, and what was
+	// found. Scripts of the embedded pipelines aren't checked.
+	CheckScripts   bool
+	ScriptFindings []ScriptFinding
+
+	// Set while compiling the steps of an embedded pipeline.
+	inBuiltin bool
 }
 
 func (c *Compiled) CompilePipelines(ctx context.Context, sm *SubstitutionMap, pipelines []config.Pipeline) error {
 	for i := range pipelines {
 		if err := c.compilePipeline(ctx, sm, &pipelines[i], nil); err != nil {
-			return fmt.Errorf("compiling Pipeline[%d]: %w", i, err)
+			return config.WithPosition(pipelines[i].Position(), fmt.Errorf("compiling Pipeline[%d]: %w", i, err))
 		}
 
 		if err := c.gatherDeps(ctx, &pipelines[i]); err != nil {
@@ -219,36 +261,29 @@ func (c *Compiled) CompilePipelines(ctx context.Context, sm *SubstitutionMap, pi
 }
 
 func (c *Compiled) compilePipeline(ctx context.Context, sm *SubstitutionMap, pipeline *config.Pipeline, parent map[string]string) error {
-	log := clog.FromContext(ctx)
 	name, uses, with := pipeline.Name, pipeline.Uses, maps.Clone(pipeline.With)
 
 	// When compiling an already-compiled config, `uses` will be redundant and FYI only,
 	// so ignore it if there is also a `pipelines` spelled out.
 	if uses != "" && len(pipeline.Pipeline) == 0 {
-		var data []byte
-		// Set this to fail up front in case there are no pipeline dirs specified
-		// and we can't find them.
-		err := fmt.Errorf("could not find 'uses' pipeline %q", uses)
-
-		for _, pd := range c.PipelineDirs {
-			log.Debugf("trying to load pipeline %q from %q", uses, pd)
-			data, err = os.ReadFile(filepath.Join(pd, uses+".yaml"))
-			if err == nil {
-				log.Debugf("Found pipeline %s", string(data))
-				break
-			}
-		}
+		data, file, builtin, err := c.loadPipeline(ctx, uses)
 		if err != nil {
-			log.Debugf("trying to load pipeline %q from embedded fs pipelines/%q.yaml", uses, uses)
-			data, err = PipelinesFS.ReadFile("pipelines/" + uses + ".yaml")
-			if err != nil {
-				return fmt.Errorf("unable to load pipeline: %w", err)
-			}
+			return err
 		}
 
-		if err := yaml.Unmarshal(data, pipeline); err != nil {
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
 			return fmt.Errorf("unable to parse pipeline %q: %w", uses, err)
 		}
+		if err := node.Decode(pipeline); err != nil {
+			return fmt.Errorf("unable to parse pipeline %q: %w", uses, err)
+		}
+		pipeline.AnnotateDefinition(file, &node)
+
+		if builtin && !c.inBuiltin {
+			c.inBuiltin = true
+			defer func() { c.inBuiltin = false }()
+		}
 
 		for k := range with {
 			if _, ok := pipeline.Inputs[k]; !ok {
@@ -293,15 +328,20 @@ func (c *Compiled) compilePipeline(ctx context.Context, sm *SubstitutionMap, pip
 		}
 	}
 
+	raw := pipeline.Runs
 	pipeline.Runs, err = util.MutateStringFromMap(mutated, pipeline.Runs)
 	if err != nil {
-		return fmt.Errorf("mutating runs: %w", err)
+		return config.WithPosition(pipeline.RunsPosition(), fmt.Errorf("mutating runs: %w", err))
+	}
+
+	if c.CheckScripts && !c.inBuiltin && pipeline.Runs != "" {
+		c.ScriptFindings = append(c.ScriptFindings, checkScript(pipeline, raw, pipeline.Runs, mutated)...)
 	}
 
 	// Drop any comments to avoid leaking things into .melange.json.
 	pipeline.Runs, err = stripComments(pipeline.Runs)
 	if err != nil {
-		return fmt.Errorf("stripping runs comments: %w", err)
+		return config.WithPosition(syntaxErrorPosition(pipeline.RunsPosition(), err), fmt.Errorf("stripping runs comments: %w", err))
 	}
 
 	if pipeline.If != "" {
@@ -320,7 +360,7 @@ func (c *Compiled) compilePipeline(ctx context.Context, sm *SubstitutionMap, pip
 		}
 
 		if err := c.compilePipeline(ctx, sm, p, mutated); err != nil {
-			return fmt.Errorf("compiling Pipeline[%d]: %w", i, err)
+			return config.WithPosition(p.Position(), fmt.Errorf("compiling Pipeline[%d]: %w", i, err))
 		}
 	}
 
@@ -346,6 +386,38 @@ func (c *Compiled) compilePipeline(ctx context.Context, sm *SubstitutionMap, pip
 	return nil
 }
 
+// loadPipeline returns the definition of the `uses` pipeline, looking at remote
+// sources, the pipeline directories and the embedded pipelines in that order.
+// It also returns the file the definition was read from, and whether it is one
+// of the embedded pipelines.
+func (c *Compiled) loadPipeline(ctx context.Context, uses string) ([]byte, string, bool, error) {
+	log := clog.FromContext(ctx)
+
+	if isRemoteUses(uses) {
+		data, file, err := c.loadRemotePipeline(ctx, uses)
+		return data, file, false, err
+	}
+
+	for _, pd := range c.PipelineDirs {
+		log.Debugf("trying to load pipeline %q from %q", uses, pd)
+		file := filepath.Join(pd, uses+".yaml")
+		data, err := os.ReadFile(file)
+		if err == nil {
+			log.Debugf("Found pipeline %s", string(data))
+			return data, file, false, nil
+		}
+	}
+
+	log.Debugf("trying to load pipeline %q from embedded fs pipelines/%q.yaml", uses, uses)
+	file := "pipelines/" + uses + ".yaml"
+	data, err := PipelinesFS.ReadFile(file)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("unable to load pipeline: %w", err)
+	}
+
+	return data, file, true, nil
+}
+
 func identity(p *config.Pipeline) string {
 	if p.Name != "" {
 		return p.Name
@@ -409,6 +481,16 @@ func maybeIncludeSyntaxError(runs string, err error) error {
 	return fmt.Errorf("%w:\n> %s\n%*s", err, lines[line-1], padding, "^")
 }
 
+// syntaxErrorPosition returns the position of a shell syntax error in a script
+// whose first line is at base.
+func syntaxErrorPosition(base config.Position, err error) config.Position {
+	var perr syntax.ParseError
+	if !errors.As(err, &perr) {
+		return base
+	}
+	return scriptPosition(base, perr.Pos)
+}
+
 func stripComments(runs string) (string, error) {
 	parser := syntax.NewParser(syntax.KeepComments(false))
 	printer := syntax.NewPrinter()
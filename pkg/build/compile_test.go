@@ -16,6 +16,8 @@ package build
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"slices"
 	"testing"
 
@@ -160,3 +162,70 @@ func Test_stripComments(t *testing.T) {
 		t.Errorf("want:\n%s\ngot:\n%s", wantErr, err)
 	}
 }
+
+func TestCompileErrorPosition(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name     string
+		pipeline string
+		wantPos  string
+	}{{
+		name: "undefined variable",
+		pipeline: `  - name: nested
+    pipeline:
+      - runs: echo ok
+      - runs: |
+          echo ok
+          echo ${{inputs.missing}}
+`,
+		wantPos: ":10",
+	}, {
+		name: "syntax error",
+		pipeline: `  - runs: echo ok
+  - runs: |
+      echo ok
+      if true; then
+        echo unterminated
+`,
+		wantPos: ":9",
+	}, {
+		name: "undefined input",
+		pipeline: `  - uses: fetch
+    with:
+      nope: bar
+`,
+		wantPos: ":6:5",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := filepath.Join(t.TempDir(), "pos.yaml")
+			if err := os.WriteFile(fp, []byte(`package:
+  name: pos
+  version: 1.0.0
+  epoch: 0
+pipeline:
+`+tt.pipeline), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := config.ParseConfiguration(ctx, fp)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b := &Build{Configuration: cfg}
+			err = b.Compile(ctx)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			pos, ok := config.ErrorPosition(err)
+			if !ok {
+				t.Fatalf("error has no position: %v", err)
+			}
+			if got, want := pos.String(), fp+tt.wantPos; got != want {
+				t.Errorf("position: want %s, got %s (%v)", want, got, err)
+			}
+		})
+	}
+}
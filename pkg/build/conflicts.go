@@ -0,0 +1,142 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	apkofs "chainguard.dev/apko/pkg/apk/fs"
+	"github.com/chainguard-dev/clog"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+// fileConflict is a path which more than one package of the build installs.
+type fileConflict struct {
+	Path     string
+	Packages []string
+}
+
+// packageReplaces returns the names of the packages each package of cfg
+// replaces.
+func packageReplaces(cfg *config.Configuration) map[string][]string {
+	names := func(deps []string) []string {
+		var out []string
+		for _, dep := range deps {
+			if i := strings.IndexAny(dep, "=<>~"); i >= 0 {
+				dep = dep[:i]
+			}
+			out = append(out, dep)
+		}
+		return out
+	}
+
+	replaces := map[string][]string{
+		cfg.Package.Name: names(cfg.Package.Dependencies.Replaces),
+	}
+	for _, sp := range cfg.Subpackages {
+		replaces[sp.Name] = names(sp.Dependencies.Replaces)
+	}
+	return replaces
+}
+
+// findFileConflicts returns the paths which more than one of the packages
+// built from cfg install, unless each of them replaces or is replaced by the
+// others. fsys is the workspace the packages were built in.
+func findFileConflicts(ctx context.Context, fsys apkofs.FullFS, cfg *config.Configuration) ([]fileConflict, error) {
+	owners := map[string][]string{}
+
+	for name := range cfg.AllPackageNames() {
+		pkgFS, err := apkofs.Sub(fsys, filepath.Join(melangeOutputDirName, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to return filesystem for workspace subtree: %w", err)
+		}
+
+		if err := fs.WalkDir(pkgFS, ".", func(path string, d fs.DirEntry, err error) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err != nil {
+				return err
+			}
+			// Packages share directories, but not what is in them.
+			if d.IsDir() {
+				return nil
+			}
+			owners[path] = append(owners[path], name)
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("walking %s: %w", name, err)
+		}
+	}
+
+	replaces := packageReplaces(cfg)
+	replacing := func(a, b string) bool {
+		return slices.Contains(replaces[a], b) || slices.Contains(replaces[b], a)
+	}
+
+	var conflicts []fileConflict
+	for _, path := range slices.Sorted(maps.Keys(owners)) {
+		pkgs := owners[path]
+		if len(pkgs) < 2 {
+			continue
+		}
+
+		conflicting := false
+		for i, a := range pkgs {
+			for _, b := range pkgs[i+1:] {
+				if !replacing(a, b) {
+					conflicting = true
+				}
+			}
+		}
+		if conflicting {
+			conflicts = append(conflicts, fileConflict{Path: path, Packages: pkgs})
+		}
+	}
+
+	return conflicts, nil
+}
+
+// checkFileConflicts fails the build if more than one of its packages
+// installs the same path, as they couldn't be installed together.
+func (b *Build) checkFileConflicts(ctx context.Context) error {
+	log := clog.FromContext(ctx)
+
+	conflicts, err := findFileConflicts(ctx, b.WorkspaceDirFS, b.Configuration)
+	if err != nil {
+		return fmt.Errorf("checking for file conflicts: %w", err)
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	for _, c := range conflicts {
+		log.Warnf("%s is installed by %s", c.Path, strings.Join(c.Packages, ", "))
+	}
+
+	if b.AllowFileConflicts {
+		log.Warnf("%d paths are installed by more than one package", len(conflicts))
+		return nil
+	}
+
+	return fmt.Errorf("%d paths are installed by more than one package; declare replaces between the packages, or remove the paths from all but one of them", len(conflicts))
+}
@@ -226,6 +226,51 @@ func WithDependencyLog(logFile string) Option {
 	}
 }
 
+// WithExplainDependencies sets whether to write why each generated
+// dependency was generated next to the emitted packages.
+func WithExplainDependencies(explain bool) Option {
+	return func(b *Build) error {
+		b.ExplainDependencies = explain
+		return nil
+	}
+}
+
+// WithAllowFileConflicts sets whether paths installed by more than one
+// package of the build only warn instead of failing it.
+func WithAllowFileConflicts(allow bool) Option {
+	return func(b *Build) error {
+		b.AllowFileConflicts = allow
+		return nil
+	}
+}
+
+// WithLintBaseline sets the lint baseline file of accepted findings, which
+// only fail the build if they are new.
+func WithLintBaseline(path string) Option {
+	return func(b *Build) error {
+		b.LintBaseline = path
+		return nil
+	}
+}
+
+// WithLintSARIF sets whether the lint findings are also written as a SARIF
+// log, next to the packages.
+func WithLintSARIF(sarif bool) Option {
+	return func(b *Build) error {
+		b.LintSARIF = sarif
+		return nil
+	}
+}
+
+// WithLintAgainst sets the repository of the previous versions of the
+// packages, which the regressions linter compares them to.
+func WithLintAgainst(repo string) Option {
+	return func(b *Build) error {
+		b.LintAgainst = repo
+		return nil
+	}
+}
+
 // WithBinShOverlay sets a filename to copy from when installing /bin/sh
 // into a build environment.
 func WithBinShOverlay(binShOverlay string) Option {
@@ -432,3 +477,11 @@ func WithGenerateProvenance(provenance bool) Option {
 		return nil
 	}
 }
+
+// WithCheckScripts sets whether to check pipeline scripts for likely mistakes.
+func WithCheckScripts(check bool) Option {
+	return func(b *Build) error {
+		b.CheckScripts = check
+		return nil
+	}
+}
@@ -38,6 +38,8 @@ import (
 	"github.com/klauspost/pgzip"
 
 	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter"
+	"chainguard.dev/melange/pkg/linter/linters"
 	"chainguard.dev/melange/pkg/sca"
 	"chainguard.dev/melange/pkg/sign"
 	"chainguard.dev/melange/pkg/tarball"
@@ -81,6 +83,8 @@ type PackageBuild struct {
 	Description   string
 	URL           string
 	Commit        string
+	// Explanations records why each generated dependency was generated.
+	Explanations sca.Explanations
 }
 
 func pkgFromSub(sub *config.Subpackage) *config.Package {
@@ -155,6 +159,10 @@ func (pc *PackageBuild) ProvenanceFilename() string {
 	return fmt.Sprintf("%s/%s.attest.tar.gz", pc.OutDir, pc.Identity())
 }
 
+func (pc *PackageBuild) ExplanationsFilename() string {
+	return fmt.Sprintf("%s/%s.sca.json", pc.OutDir, pc.Identity())
+}
+
 func (pc *PackageBuild) WorkspaceSubdir() string {
 	return filepath.Join(pc.Build.WorkspaceDir, melangeOutputDirName, pc.PackageName)
 }
@@ -339,7 +347,8 @@ func (pc *PackageBuild) GenerateDependencies(ctx context.Context, hdl sca.SCAHan
 	log := clog.FromContext(ctx)
 	generated := config.Dependencies{}
 
-	if err := sca.Analyze(ctx, hdl, &generated); err != nil {
+	explanations, err := sca.Explain(ctx, hdl, &generated)
+	if err != nil {
 		return fmt.Errorf("analyzing package: %w", err)
 	}
 
@@ -377,9 +386,71 @@ func (pc *PackageBuild) GenerateDependencies(ctx context.Context, hdl sca.SCAHan
 
 	pc.Dependencies.Summarize(ctx)
 
+	// Leave out the generated dependencies dropped in favor of configured or
+	// self-provided ones.
+	pc.Explanations = explanations.For(pc.Dependencies)
+
+	if pc.Build.ExplainDependencies {
+		if err := pc.writeExplanations(); err != nil {
+			return fmt.Errorf("writing dependency explanations: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// lintDependencies runs the linters which check the final dependencies of the
+// package against what it contains.
+func (pc *PackageBuild) lintDependencies(ctx context.Context, fsys fs.FS) error {
+	b := pc.Build
+	if b.Configuration == nil {
+		return nil
+	}
+
+	disabled := b.Configuration.Package.Checks.Disabled
+	for _, sp := range b.Configuration.Subpackages {
+		if sp.Name == pc.PackageName {
+			disabled = sp.Checks.Disabled
+		}
+	}
+
+	siblings, err := b.lintSiblings(pc.PackageName)
+	if err != nil {
+		return err
+	}
+	ctx = linters.WithSiblings(ctx, siblings)
+	ctx = linter.WithPackageDir(ctx, filepath.Join(b.WorkspaceDir, melangeOutputDirName, pc.PackageName))
+
+	deps := linters.Dependencies{
+		Runtime:  pc.Dependencies.Runtime,
+		Provides: pc.Dependencies.Provides,
+		Resolver: b.PkgResolver,
+	}
+
+	require, warn := b.linters(disabled)
+	return linter.LintDependencies(ctx, b.Configuration, pc.PackageName, require, warn, fsys, deps, b.lintOutDir(), pc.Arch)
+}
+
+func (pc *PackageBuild) writeExplanations() error {
+	if err := os.MkdirAll(pc.OutDir, 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(pc.ExplanationsFilename())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	je := json.NewEncoder(f)
+	je.SetIndent("", "  ")
+	if err := je.Encode(pc.Explanations); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
 func combine(out io.Writer, inputs ...io.Reader) error {
 	for _, input := range inputs {
 		if _, err := io.Copy(out, input); err != nil {
@@ -519,6 +590,10 @@ func (pc *PackageBuild) EmitPackage(ctx context.Context) error {
 		return fmt.Errorf("unable to build final dependencies set: %w", err)
 	}
 
+	if err := pc.lintDependencies(ctx, fsys); err != nil {
+		return fmt.Errorf("unable to lint package %s: %w", pc.PackageName, err)
+	}
+
 	// walk the filesystem to calculate the installed-size
 	if err := pc.calculateInstalledSize(fsys); err != nil {
 		return err
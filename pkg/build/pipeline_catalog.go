@@ -0,0 +1,221 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	apko_types "chainguard.dev/apko/pkg/build/types"
+	"gopkg.in/yaml.v3"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+// BuiltinPipelineSource is the source reported for pipelines embedded in melange.
+const BuiltinPipelineSource = "builtin"
+
+// inputRefRegexp matches references to pipeline inputs, e.g. ${{inputs.foo}}.
+var inputRefRegexp = regexp.MustCompile(`\$\{\{\s*inputs\.([A-Za-z0-9_-]+)\s*\}\}`)
+
+// PipelineInfo describes a pipeline that can be referenced with `uses`.
+type PipelineInfo struct {
+	// The name used to reference the pipeline, e.g. "go/build".
+	Name string `json:"name"`
+	// Either the pipeline directory the pipeline was found in, or "builtin".
+	Source string `json:"source"`
+	// The definition of the pipeline.
+	Pipeline config.Pipeline `json:"pipeline"`
+}
+
+// ListPipelines returns every pipeline that a `uses` can resolve to, sorted by
+// name. Pipelines in pipelineDirs shadow the builtin pipelines, following the
+// same resolution order as compilation.
+func ListPipelines(pipelineDirs []string) ([]PipelineInfo, error) {
+	found := map[string]PipelineInfo{}
+
+	add := func(fsys fs.FS, root, source string) error {
+		return fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || path.Ext(p) != ".yaml" {
+				return nil
+			}
+
+			name := strings.TrimSuffix(strings.TrimPrefix(p, root+"/"), ".yaml")
+			if _, ok := found[name]; ok {
+				return nil
+			}
+
+			data, err := fs.ReadFile(fsys, p)
+			if err != nil {
+				return err
+			}
+
+			var pipeline config.Pipeline
+			if err := yaml.Unmarshal(data, &pipeline); err != nil {
+				return fmt.Errorf("unable to parse pipeline %q: %w", name, err)
+			}
+
+			found[name] = PipelineInfo{Name: name, Source: source, Pipeline: pipeline}
+			return nil
+		})
+	}
+
+	for _, pd := range pipelineDirs {
+		if err := add(os.DirFS(pd), ".", pd); err != nil {
+			return nil, fmt.Errorf("listing pipelines in %s: %w", pd, err)
+		}
+	}
+	if err := add(PipelinesFS, "pipelines", BuiltinPipelineSource); err != nil {
+		return nil, fmt.Errorf("listing builtin pipelines: %w", err)
+	}
+
+	infos := make([]PipelineInfo, 0, len(found))
+	for _, name := range slices.Sorted(maps.Keys(found)) {
+		infos = append(infos, found[name])
+	}
+
+	return infos, nil
+}
+
+// DescribePipeline returns the pipeline that `uses: name` resolves to.
+func DescribePipeline(pipelineDirs []string, name string) (*PipelineInfo, error) {
+	infos, err := ListPipelines(pipelineDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, info := range infos {
+		if info.Name == name {
+			return &info, nil
+		}
+	}
+
+	return nil, fmt.Errorf("could not find 'uses' pipeline %q", name)
+}
+
+// PipelineProblem is an issue found while validating a pipeline definition.
+type PipelineProblem struct {
+	// The name of the pipeline the problem was found in.
+	Pipeline string `json:"pipeline"`
+	// A description of the problem.
+	Message string `json:"message"`
+}
+
+func (p PipelineProblem) String() string {
+	return fmt.Sprintf("%s: %s", p.Pipeline, p.Message)
+}
+
+// ValidatePipelines compiles every pipeline definition found in dir, and
+// reports references to undefined inputs, inputs that are never used and shell
+// syntax errors. Nested `uses` are resolved from dir, then pipelineDirs, and
+// finally the builtin pipelines.
+func ValidatePipelines(ctx context.Context, dir string, pipelineDirs []string) ([]PipelineProblem, error) {
+	infos, err := ListPipelines([]string{dir})
+	if err != nil {
+		return nil, err
+	}
+
+	sm, err := NewSubstitutionMap(&config.Configuration{
+		Package: config.Package{Name: "validate", Version: "0"},
+	}, apko_types.ParseArchitecture("x86_64"), "gnu", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pipelines may be used by subpackages, so validate them in that context
+	// to have all variables defined.
+	sm = sm.Subpackage(&config.Subpackage{Name: "validate"})
+
+	var problems []PipelineProblem
+	for _, info := range infos {
+		if info.Source != dir {
+			continue
+		}
+
+		referenced := map[string]bool{}
+		collectInputRefs(&info.Pipeline, referenced)
+
+		// Substitute a placeholder for undefined inputs, so we can still
+		// find the problems that compilation would report after them.
+		psm := &SubstitutionMap{Substitutions: maps.Clone(sm.Substitutions)}
+		for _, in := range slices.Sorted(maps.Keys(referenced)) {
+			if _, ok := info.Pipeline.Inputs[in]; !ok {
+				problems = append(problems, PipelineProblem{
+					Pipeline: info.Name,
+					Message:  fmt.Sprintf("reference to undefined input %q", in),
+				})
+				psm.Substitutions[fmt.Sprintf("${{inputs.%s}}", in)] = "placeholder"
+			}
+		}
+		for _, in := range slices.Sorted(maps.Keys(info.Pipeline.Inputs)) {
+			if !referenced[in] {
+				problems = append(problems, PipelineProblem{
+					Pipeline: info.Name,
+					Message:  fmt.Sprintf("input %q is never used", in),
+				})
+			}
+		}
+
+		// Provide a value for required inputs, as a consumer would have to.
+		with := map[string]string{}
+		for k, v := range info.Pipeline.Inputs {
+			if v.Required && v.Default == "" {
+				with[k] = "placeholder"
+			}
+		}
+
+		c := &Compiled{PipelineDirs: append([]string{dir}, pipelineDirs...)}
+		p := config.Pipeline{Uses: info.Name, With: with}
+		if err := c.compilePipeline(ctx, psm, &p, nil); err != nil {
+			problems = append(problems, PipelineProblem{
+				Pipeline: info.Name,
+				Message:  err.Error(),
+			})
+		}
+	}
+
+	return problems, nil
+}
+
+// collectInputRefs records every input referenced by the pipeline, including
+// references from its nested steps, but not from the pipelines they use.
+func collectInputRefs(p *config.Pipeline, refs map[string]bool) {
+	fields := []string{p.If, p.Runs, p.WorkDir}
+	fields = append(fields, slices.Collect(maps.Values(p.With))...)
+	fields = append(fields, slices.Collect(maps.Values(p.Environment))...)
+	if p.Needs != nil {
+		fields = append(fields, p.Needs.Packages...)
+	}
+
+	for _, f := range fields {
+		for _, m := range inputRefRegexp.FindAllStringSubmatch(f, -1) {
+			refs[m[1]] = true
+		}
+	}
+
+	for i := range p.Pipeline {
+		collectInputRefs(&p.Pipeline[i], refs)
+	}
+}
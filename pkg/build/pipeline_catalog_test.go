@@ -0,0 +1,117 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListPipelines(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "go"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go", "build.yaml"), []byte("name: shadowed\nruns: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := ListPipelines([]string{dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sources := map[string]string{}
+	for _, info := range infos {
+		sources[info.Name] = info.Source
+	}
+
+	if got, want := sources["go/build"], dir; got != want {
+		t.Errorf("go/build: want source %q, got %q", want, got)
+	}
+	if got, want := sources["autoconf/make"], BuiltinPipelineSource; got != want {
+		t.Errorf("autoconf/make: want source %q, got %q", want, got)
+	}
+
+	info, err := DescribePipeline(nil, "go/build")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !info.Pipeline.Inputs["packages"].Required {
+		t.Errorf("go/build: expected input packages to be required")
+	}
+
+	if _, err := DescribePipeline(nil, "does/not/exist"); err == nil {
+		t.Errorf("expected error describing a missing pipeline")
+	}
+}
+
+func TestValidatePipelines(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"good.yaml": `
+inputs:
+  who:
+    required: true
+runs: echo hello ${{inputs.who}}
+`,
+		"undefined.yaml": `
+pipeline:
+  - runs: echo ${{inputs.missing}}
+`,
+		"unused.yaml": `
+inputs:
+  extra:
+    default: foo
+runs: echo hello
+`,
+		"syntax.yaml": `
+runs: |
+  if [[ uname -m == 'x86_64']]; then
+    echo hi
+  fi
+`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	problems, err := ValidatePipelines(context.Background(), dir, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := map[string][]string{}
+	for _, p := range problems {
+		got[p.Pipeline] = append(got[p.Pipeline], p.Message)
+	}
+
+	if len(got["good"]) != 0 {
+		t.Errorf("good: unexpected problems %v", got["good"])
+	}
+	if msgs := got["undefined"]; len(msgs) != 1 || !strings.Contains(msgs[0], `undefined input "missing"`) {
+		t.Errorf("undefined: want an undefined input problem, got %v", msgs)
+	}
+	if msgs := got["unused"]; len(msgs) != 1 || !strings.Contains(msgs[0], `input "extra" is never used`) {
+		t.Errorf("unused: want an unused input problem, got %v", msgs)
+	}
+	if msgs := got["syntax"]; len(msgs) != 1 || !strings.Contains(msgs[0], "not a valid test operator") {
+		t.Errorf("syntax: want a shell syntax problem, got %v", msgs)
+	}
+}
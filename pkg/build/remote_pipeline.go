@@ -0,0 +1,423 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	intoto "github.com/in-toto/attestation/go/v1"
+	purl "github.com/package-url/packageurl-go"
+
+	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/sbom"
+)
+
+const (
+	remotePipelineGit = "git"
+	remotePipelineOCI = "oci"
+)
+
+var (
+	gitCommitRegexp = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+	ociDigestRegexp = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+)
+
+// RemotePipeline describes a `uses` pipeline that is fetched from a git
+// repository or an OCI artifact instead of the pipeline directories.
+//
+// Remote pipelines are referenced as:
+//
+//	uses: git+https://github.com/org/repo//pipelines/go/build@<commit>
+//	uses: oci://ghcr.io/org/pipelines//go/build@sha256:<digest>
+//
+// The part before "//" locates the repository, the part after it is the path
+// of the pipeline (without the .yaml suffix) inside of it, and the part after
+// "@" is the pin that the fetched contents are verified against.
+type RemotePipeline struct {
+	// The `uses` reference as written in the configuration.
+	Uses string `json:"uses" yaml:"uses"`
+	// Either "git" or "oci".
+	Scheme string `json:"scheme" yaml:"scheme"`
+	// The git repository URL or OCI repository.
+	Location string `json:"location" yaml:"location"`
+	// The path of the pipeline within the repository, without the .yaml suffix.
+	Path string `json:"path" yaml:"path"`
+	// The full commit hash or OCI manifest digest the pipeline is pinned to.
+	Ref string `json:"ref" yaml:"ref"`
+}
+
+// isRemoteUses reports whether uses refers to a remote pipeline.
+func isRemoteUses(uses string) bool {
+	return strings.HasPrefix(uses, "git+") || strings.HasPrefix(uses, "oci://")
+}
+
+// parseRemoteUses parses a remote `uses` reference.
+func parseRemoteUses(uses string) (*RemotePipeline, error) {
+	rp := &RemotePipeline{Uses: uses}
+
+	var rest string
+	switch {
+	case strings.HasPrefix(uses, "git+"):
+		rp.Scheme = remotePipelineGit
+		rest = strings.TrimPrefix(uses, "git+")
+	case strings.HasPrefix(uses, "oci://"):
+		rp.Scheme = remotePipelineOCI
+		rest = strings.TrimPrefix(uses, "oci://")
+	default:
+		return nil, fmt.Errorf("unsupported remote pipeline %q", uses)
+	}
+
+	at := strings.LastIndex(rest, "@")
+	if at == -1 {
+		return nil, fmt.Errorf("remote pipeline %q must be pinned with @<ref>", uses)
+	}
+	rest, rp.Ref = rest[:at], rest[at+1:]
+
+	// Skip past the URL scheme separator, if any, so we don't mistake it
+	// for the repository/path separator.
+	offset := 0
+	if i := strings.Index(rest, "://"); i != -1 {
+		offset = i + len("://")
+	}
+	sep := strings.Index(rest[offset:], "//")
+	if sep == -1 {
+		return nil, fmt.Errorf("remote pipeline %q is missing the //<path> to the pipeline", uses)
+	}
+	rp.Location, rp.Path = rest[:offset+sep], strings.TrimSuffix(rest[offset+sep+2:], ".yaml")
+
+	if rp.Location == "" || rp.Path == "" {
+		return nil, fmt.Errorf("remote pipeline %q must specify both a repository and a path", uses)
+	}
+	if clean := path.Clean(rp.Path); clean != rp.Path || path.IsAbs(clean) || strings.HasPrefix(clean, "..") {
+		return nil, fmt.Errorf("remote pipeline %q has an invalid path %q", uses, rp.Path)
+	}
+
+	switch rp.Scheme {
+	case remotePipelineGit:
+		if !gitCommitRegexp.MatchString(rp.Ref) {
+			return nil, fmt.Errorf("remote pipeline %q must be pinned to a full commit hash, got %q", uses, rp.Ref)
+		}
+	case remotePipelineOCI:
+		if !ociDigestRegexp.MatchString(rp.Ref) {
+			return nil, fmt.Errorf("remote pipeline %q must be pinned to a sha256 digest, got %q", uses, rp.Ref)
+		}
+		if _, err := name.NewDigest(rp.Location + "@" + rp.Ref); err != nil {
+			return nil, fmt.Errorf("parsing remote pipeline %q: %w", uses, err)
+		}
+	}
+
+	return rp, nil
+}
+
+// cacheDir returns the directory the pinned contents are fetched into.
+func (rp *RemotePipeline) cacheDir(root string) string {
+	return filepath.Join(root, "pipelines", rp.Scheme, config.SHA256(rp.Location), strings.ReplaceAll(rp.Ref, ":", "-"))
+}
+
+// fetch makes the pinned contents of the remote pipeline's repository available
+// below the given cache root, and returns the path to the pipeline definition.
+// Contents are fetched only once and reused on subsequent calls.
+func (rp *RemotePipeline) fetch(ctx context.Context, root string) (string, error) {
+	log := clog.FromContext(ctx)
+	dir := rp.cacheDir(root)
+
+	if _, err := os.Stat(dir); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+			return "", fmt.Errorf("mkdir -p %s: %w", filepath.Dir(dir), err)
+		}
+
+		// Fetch into a temporary directory first, so an interrupted fetch
+		// never leaves a partial tree behind that looks like a cache hit.
+		tmp, err := os.MkdirTemp(filepath.Dir(dir), ".fetch-*")
+		if err != nil {
+			return "", fmt.Errorf("creating temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmp)
+
+		log.Infof("fetching remote pipeline %s@%s", rp.Location, rp.Ref)
+		switch rp.Scheme {
+		case remotePipelineGit:
+			err = rp.fetchGit(ctx, tmp)
+		case remotePipelineOCI:
+			err = rp.fetchOCI(ctx, tmp)
+		}
+		if err != nil {
+			return "", fmt.Errorf("fetching remote pipeline %q: %w", rp.Uses, err)
+		}
+
+		if err := os.Rename(tmp, dir); err != nil && !errors.Is(err, os.ErrExist) {
+			return "", fmt.Errorf("populating cache for remote pipeline %q: %w", rp.Uses, err)
+		}
+	} else if rp.Scheme == remotePipelineGit {
+		// The cache lives on disk and may have been modified, so make sure
+		// that the checkout still matches the pin. OCI artifacts are only
+		// verified against their digest when they are fetched, since
+		// the extracted files can't be checked against it.
+		if err := verifyGitCheckout(dir, rp.Ref); err != nil {
+			return "", fmt.Errorf("verifying cached remote pipeline %q: %w", rp.Uses, err)
+		}
+	}
+
+	return filepath.Join(dir, filepath.FromSlash(rp.Path)+".yaml"), nil
+}
+
+func (rp *RemotePipeline) fetchGit(ctx context.Context, dir string) error {
+	repo, err := git.PlainCloneContext(ctx, dir, false, &git.CloneOptions{
+		URL:        rp.Location,
+		NoCheckout: true,
+	})
+	if err != nil {
+		return fmt.Errorf("cloning %s: %w", rp.Location, err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	if err := wt.Checkout(&git.CheckoutOptions{Hash: plumbing.NewHash(rp.Ref), Force: true}); err != nil {
+		return fmt.Errorf("checking out %s: %w", rp.Ref, err)
+	}
+
+	return verifyGitCheckout(dir, rp.Ref)
+}
+
+func verifyGitCheckout(dir, commit string) error {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	if got := head.Hash().String(); got != commit {
+		return fmt.Errorf("expected commit %s, got %s", commit, got)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return err
+	}
+	if !status.IsClean() {
+		return fmt.Errorf("checkout of %s has been modified", commit)
+	}
+
+	return nil
+}
+
+func (rp *RemotePipeline) fetchOCI(ctx context.Context, dir string) error {
+	ref, err := name.NewDigest(rp.Location + "@" + rp.Ref)
+	if err != nil {
+		return err
+	}
+
+	img, err := remote.Image(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return fmt.Errorf("pulling %s: %w", ref, err)
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return err
+	}
+	if digest.String() != rp.Ref {
+		return fmt.Errorf("expected digest %s, got %s", rp.Ref, digest)
+	}
+
+	rc := mutate.Extract(img)
+	defer rc.Close()
+
+	return extractPipelineTar(rc, dir)
+}
+
+// extractPipelineTar extracts the regular files and directories of a tar
+// stream into dir, refusing entries that would escape it.
+func extractPipelineTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(path.Clean("/"+hdr.Name)))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(filepath.Separator)) {
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+			if err != nil {
+				return err
+			}
+			// #nosec G110 - Pipeline artifacts are pinned by digest
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// SBOMPackage returns an SBOM package describing the remote pipeline as a
+// build input.
+func (rp RemotePipeline) SBOMPackage(supplier string) (*sbom.Package, error) {
+	var pu *purl.PackageURL
+	var pkgName string
+
+	switch rp.Scheme {
+	case remotePipelineGit:
+		u, err := url.Parse(rp.Location)
+		if err != nil {
+			return nil, err
+		}
+		trimmedPath := strings.TrimSuffix(strings.TrimPrefix(u.Path, "/"), ".git")
+		namespace, repoName, _ := strings.Cut(trimmedPath, "/")
+		pkgName = repoName
+
+		switch u.Host {
+		case "github.com":
+			pu = &purl.PackageURL{Type: purl.TypeGithub, Namespace: namespace, Name: repoName, Version: rp.Ref, Subpath: rp.Path}
+		case "gitlab.com":
+			pu = &purl.PackageURL{Type: purl.TypeGitlab, Namespace: namespace, Name: repoName, Version: rp.Ref, Subpath: rp.Path}
+		default:
+			pkgName = path.Base(trimmedPath)
+			pu = &purl.PackageURL{
+				Type:       purl.TypeGeneric,
+				Name:       pkgName,
+				Version:    rp.Ref,
+				Qualifiers: purl.QualifiersFromMap(map[string]string{"vcs_url": "git+" + rp.Location + "@" + rp.Ref}),
+				Subpath:    rp.Path,
+			}
+		}
+
+	case remotePipelineOCI:
+		pkgName = path.Base(rp.Location)
+		pu = &purl.PackageURL{
+			Type:       purl.TypeOCI,
+			Name:       pkgName,
+			Version:    rp.Ref,
+			Qualifiers: purl.QualifiersFromMap(map[string]string{"repository_url": rp.Location}),
+			Subpath:    rp.Path,
+		}
+	}
+
+	if err := pu.Normalize(); err != nil {
+		return nil, fmt.Errorf("normalizing PURL: %w", err)
+	}
+
+	return &sbom.Package{
+		IDComponents: []string{"pipeline", rp.Location, rp.Path, rp.Ref},
+		Name:         pkgName + "//" + rp.Path,
+		Version:      rp.Ref,
+		Namespace:    supplier,
+		PURL:         pu,
+	}, nil
+}
+
+// resourceDescriptor returns the remote pipeline as a SLSA resolved dependency.
+func (rp RemotePipeline) resourceDescriptor() *intoto.ResourceDescriptor {
+	rd := &intoto.ResourceDescriptor{Name: rp.Path}
+
+	switch rp.Scheme {
+	case remotePipelineGit:
+		rd.Uri = "git+" + rp.Location + "@" + rp.Ref
+		rd.Digest = map[string]string{"gitCommit": rp.Ref}
+	case remotePipelineOCI:
+		algo, hex, _ := strings.Cut(rp.Ref, ":")
+		rd.Uri = "oci://" + rp.Location + "@" + rp.Ref
+		rd.Digest = map[string]string{algo: hex}
+	}
+
+	return rd
+}
+
+// loadRemotePipeline resolves a remote `uses` reference, records it as an
+// input to the build and returns the pipeline definition, along with the file
+// it was read from.
+func (c *Compiled) loadRemotePipeline(ctx context.Context, uses string) ([]byte, string, error) {
+	rp, err := parseRemoteUses(uses)
+	if err != nil {
+		return nil, "", err
+	}
+
+	root := c.CacheDir
+	if root == "" {
+		if root, err = os.UserCacheDir(); err != nil {
+			return nil, "", fmt.Errorf("no cache dir to fetch remote pipeline %q into: %w", uses, err)
+		}
+		root = filepath.Join(root, "melange")
+	}
+
+	p, err := rp.fetch(ctx, root)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to load remote pipeline %q: %w", uses, err)
+	}
+
+	if !slices.ContainsFunc(c.Remotes, func(r RemotePipeline) bool { return r.Uses == rp.Uses }) {
+		c.Remotes = append(c.Remotes, *rp)
+	}
+
+	return data, p, nil
+}
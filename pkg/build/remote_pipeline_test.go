@@ -0,0 +1,164 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+func TestParseRemoteUses(t *testing.T) {
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		uses    string
+		want    *RemotePipeline
+		wantErr string
+	}{{
+		uses: "git+https://github.com/org/repo//pipelines/go/build@" + commit,
+		want: &RemotePipeline{Scheme: "git", Location: "https://github.com/org/repo", Path: "pipelines/go/build", Ref: commit},
+	}, {
+		uses: "git+https://github.com/org/repo//go/build.yaml@" + commit,
+		want: &RemotePipeline{Scheme: "git", Location: "https://github.com/org/repo", Path: "go/build", Ref: commit},
+	}, {
+		uses: "oci://ghcr.io/org/pipelines//go/build@" + digest,
+		want: &RemotePipeline{Scheme: "oci", Location: "ghcr.io/org/pipelines", Path: "go/build", Ref: digest},
+	}, {
+		uses:    "git+https://github.com/org/repo//go/build",
+		wantErr: "must be pinned",
+	}, {
+		uses:    "git+https://github.com/org/repo//go/build@main",
+		wantErr: "full commit hash",
+	}, {
+		uses:    "git+https://github.com/org/repo@" + commit,
+		wantErr: "missing the //<path>",
+	}, {
+		uses:    "git+https://github.com/org/repo//../build@" + commit,
+		wantErr: "invalid path",
+	}, {
+		uses:    "oci://ghcr.io/org/pipelines//go/build@latest",
+		wantErr: "sha256 digest",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.uses, func(t *testing.T) {
+			got, err := parseRemoteUses(tt.uses)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("want error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			tt.want.Uses = tt.uses
+			if *got != *tt.want {
+				t.Errorf("want %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCompileRemoteGitPipeline(t *testing.T) {
+	ctx := context.Background()
+
+	src := t.TempDir()
+	repo, err := git.PlainInit(src, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(src, "pipelines", "greet"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "pipelines", "greet", "hello.yaml"), []byte(`
+inputs:
+  who:
+    default: world
+needs:
+  packages:
+    - busybox
+runs: echo hello ${{inputs.who}}
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add("pipelines"); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := wt.Commit("add pipeline", &git.CommitOptions{
+		Author: &object.Signature{Name: "melange", Email: "melange@example.com", When: time.Unix(0, 0)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uses := "git+file://" + src + "//pipelines/greet/hello@" + hash.String()
+	cacheDir := t.TempDir()
+
+	for range 2 {
+		c := &Compiled{CacheDir: cacheDir}
+		pipelines := []config.Pipeline{{
+			Uses: uses,
+			With: map[string]string{"who": "melange"},
+		}}
+
+		if err := c.CompilePipelines(ctx, &SubstitutionMap{Substitutions: map[string]string{}}, pipelines); err != nil {
+			t.Fatalf("compiling: %v", err)
+		}
+
+		if got, want := pipelines[0].Runs, "echo hello melange\n"; got != want {
+			t.Errorf("runs: want %q, got %q", want, got)
+		}
+		if got, want := c.Needs, []string{"busybox"}; !slices.Equal(got, want) {
+			t.Errorf("needs: want %v, got %v", want, got)
+		}
+		if len(c.Remotes) != 1 || c.Remotes[0].Ref != hash.String() {
+			t.Errorf("remotes: want one pinned to %s, got %+v", hash, c.Remotes)
+		}
+	}
+
+	// A cached checkout that was modified must not be used.
+	cached := filepath.Join((&RemotePipeline{Scheme: remotePipelineGit, Location: "file://" + src, Ref: hash.String()}).cacheDir(cacheDir), "pipelines", "greet", "hello.yaml")
+	if err := os.WriteFile(cached, []byte("runs: echo tampered\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := &Compiled{CacheDir: cacheDir}
+	if err := c.CompilePipelines(ctx, &SubstitutionMap{Substitutions: map[string]string{}}, []config.Pipeline{{Uses: uses}}); err == nil || !strings.Contains(err.Error(), "modified") {
+		t.Errorf("expected modified checkout error, got %v", err)
+	}
+
+	// A pin that doesn't exist in the repository must not resolve.
+	c = &Compiled{CacheDir: cacheDir}
+	bad := "git+file://" + src + "//pipelines/greet/hello@" + strings.Repeat("f", 40)
+	if err := c.CompilePipelines(ctx, &SubstitutionMap{Substitutions: map[string]string{}}, []config.Pipeline{{Uses: bad}}); err == nil {
+		t.Errorf("expected error compiling %q", bad)
+	}
+}
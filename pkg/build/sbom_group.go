@@ -58,6 +58,15 @@ func (sg *SBOMGroup) AddBuildConfigurationPackage(p *sbom.Package) {
 	}
 }
 
+// AddBuildConfigurationInclude adds a fragment included by the build
+// configuration package to all SBOMs in the group.
+func (sg *SBOMGroup) AddBuildConfigurationInclude(config, p *sbom.Package) {
+	for _, doc := range sg.set {
+		doc.AddPackage(p)
+		doc.AddRelationship(config, p, common.TypeRelationshipDependsOn)
+	}
+}
+
 // AddUpstreamSourcePackage adds a package serving as an "upstream source
 // package" to all SBOMs in the group.
 func (sg *SBOMGroup) AddUpstreamSourcePackage(p *sbom.Package) {
@@ -66,3 +75,12 @@ func (sg *SBOMGroup) AddUpstreamSourcePackage(p *sbom.Package) {
 		doc.AddRelationship(doc.Describes, p, common.TypeRelationshipGeneratedFrom)
 	}
 }
+
+// AddBuildInputPackage adds a package serving as an additional input to the
+// build, such as a remote pipeline, to all SBOMs in the group.
+func (sg *SBOMGroup) AddBuildInputPackage(p *sbom.Package) {
+	for _, doc := range sg.set {
+		doc.AddPackage(p)
+		doc.AddRelationship(p, doc.Describes, common.TypeRelationshipBuildDependencyOf)
+	}
+}
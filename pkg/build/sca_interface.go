@@ -55,6 +55,11 @@ func (scabi *SCABuildInterface) Version() string {
 	return fmt.Sprintf("%s-r%d", scabi.PackageBuild.Origin.Version, scabi.PackageBuild.Origin.Epoch)
 }
 
+// Arch returns the APK architecture of the package being built.
+func (scabi *SCABuildInterface) Arch() string {
+	return scabi.PackageBuild.Arch
+}
+
 // FilesystemForRelative implements an abstract filesystem for any of the packages being
 // built.
 func (scabi *SCABuildInterface) FilesystemForRelative(pkgName string) (sca.SCAFS, error) {
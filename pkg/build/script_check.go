@@ -0,0 +1,389 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+// The checks run on pipeline scripts by --check-scripts.
+const (
+	CheckUnquotedSubstitution = "unquoted-substitution"
+	CheckUncheckedCd          = "unchecked-cd"
+	CheckUsrLocal             = "usr-local"
+	CheckWriteOutsideDestdir  = "write-outside-destdir"
+	CheckCurlPipeShell        = "curl-pipe-shell"
+	CheckDisableErrexit       = "set-plus-e"
+)
+
+// substitutionRegexp matches a melange substitution, e.g. ${{inputs.foo}}.
+var substitutionRegexp = regexp.MustCompile(`\$\{\{ *([a-zA-Z0-9._-]+) *\}\}`)
+
+// writablePrefixes are the absolute paths scripts are expected to write to.
+var writablePrefixes = []string{WorkDir, "/tmp", "/dev", "/proc/self", "/var/cache/melange"}
+
+// ScriptFinding is a likely mistake found in a pipeline script.
+type ScriptFinding struct {
+	// The name of the check that produced the finding.
+	Check string `json:"check"`
+	// A description of the problem.
+	Message string `json:"message"`
+	// The name of the pipeline the script belongs to.
+	Pipeline string `json:"pipeline"`
+	// Where in the configuration, or pipeline definition, the problem is.
+	Position config.Position `json:"position"`
+}
+
+func (f ScriptFinding) String() string {
+	return fmt.Sprintf("%s: %s [%s]", f.Position, f.Message, f.Check)
+}
+
+// scriptChecker walks the shell AST of a single pipeline script.
+type scriptChecker struct {
+	pipeline string
+	pos      config.Position
+	findings []ScriptFinding
+}
+
+// checkScript looks for likely mistakes in a pipeline script. raw is the
+// script before substitution, and runs after substituting the values in
+// mutated. Scripts that don't parse are skipped, as compilation reports them.
+func checkScript(pipeline *config.Pipeline, raw, runs string, mutated map[string]string) []ScriptFinding {
+	sc := &scriptChecker{
+		pipeline: identity(pipeline),
+		pos:      pipeline.RunsPosition(),
+	}
+
+	sc.checkSubstitutions(raw, mutated)
+
+	if f, err := syntax.NewParser().Parse(strings.NewReader(runs), ""); err == nil {
+		sc.checkCommands(f)
+	}
+
+	return sc.findings
+}
+
+// position maps a position in the script to a position in the YAML file.
+func (sc *scriptChecker) position(p syntax.Pos) config.Position {
+	return scriptPosition(sc.pos, p)
+}
+
+// scriptPosition maps a position in a script whose first line is at base to
+// a position in the YAML file. Columns are only known on the first line.
+func scriptPosition(base config.Position, p syntax.Pos) config.Position {
+	if base.IsZero() || !p.IsValid() {
+		return base
+	}
+
+	base.Line += int(p.Line()) - 1
+	if p.Line() == 1 && base.Column != 0 {
+		base.Column += int(p.Col()) - 1
+	} else {
+		base.Column = 0
+	}
+
+	return base
+}
+
+func (sc *scriptChecker) report(check string, p syntax.Pos, format string, args ...any) {
+	sc.findings = append(sc.findings, ScriptFinding{
+		Check:    check,
+		Message:  fmt.Sprintf(format, args...),
+		Pipeline: sc.pipeline,
+		Position: sc.position(p),
+	})
+}
+
+// checkSubstitutions reports substitutions that expand to a value with
+// whitespace, but aren't quoted, so the shell splits them into several words.
+// To find them, every substitution is replaced by a placeholder of the same
+// length before parsing the script.
+func (sc *scriptChecker) checkSubstitutions(raw string, mutated map[string]string) {
+	matches := substitutionRegexp.FindAllStringSubmatchIndex(raw, -1)
+	if len(matches) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(raw[last:m[0]])
+		b.WriteString(strings.Repeat("_", m[1]-m[0]))
+		last = m[1]
+	}
+	b.WriteString(raw[last:])
+
+	f, err := syntax.NewParser().Parse(strings.NewReader(b.String()), "")
+	if err != nil {
+		return
+	}
+
+	// Words that the shell doesn't split.
+	nosplit := map[*syntax.Word]bool{}
+
+	syntax.Walk(f, func(node syntax.Node) bool {
+		switch n := node.(type) {
+		case *syntax.Assign:
+			nosplit[n.Value] = true
+		case *syntax.CaseClause:
+			nosplit[n.Word] = true
+		case *syntax.TestClause:
+			syntax.Walk(n.X, func(node syntax.Node) bool {
+				if w, ok := node.(*syntax.Word); ok {
+					nosplit[w] = true
+				}
+				return true
+			})
+		case *syntax.Word:
+			if nosplit[n] {
+				return true
+			}
+			for _, part := range n.Parts {
+				lit, ok := part.(*syntax.Lit)
+				if !ok {
+					continue
+				}
+				start, end := int(lit.Pos().Offset()), int(lit.End().Offset())
+				for _, m := range matches {
+					if m[1] <= start || m[0] >= end {
+						continue
+					}
+					key := raw[m[2]:m[3]]
+					value, ok := mutated["${{"+key+"}}"]
+					if !ok || !strings.ContainsAny(value, " \t\n") {
+						continue
+					}
+					sc.report(CheckUnquotedSubstitution, lit.Pos(), "${{%s}} expands to a value with whitespace but is not quoted", key)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// checkCommands walks the substituted script looking for commands that are
+// likely mistakes.
+func (sc *scriptChecker) checkCommands(f *syntax.File) {
+	// Statements whose failure is handled by the script.
+	handled := map[*syntax.Stmt]bool{}
+	// Scripts run with `set -e`, until they turn it off.
+	errexit := true
+
+	syntax.Walk(f, func(node syntax.Node) bool {
+		switch n := node.(type) {
+		case *syntax.BinaryCmd:
+			switch n.Op {
+			case syntax.AndStmt, syntax.OrStmt:
+				handled[n.X] = true
+				handled[n.Y] = true
+			case syntax.Pipe, syntax.PipeAll:
+				sc.checkPipe(n)
+			}
+		case *syntax.IfClause:
+			for _, s := range n.Cond {
+				handled[s] = true
+			}
+		case *syntax.WhileClause:
+			for _, s := range n.Cond {
+				handled[s] = true
+			}
+		case *syntax.Stmt:
+			call, ok := n.Cmd.(*syntax.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				break
+			}
+			switch call.Args[0].Lit() {
+			case "set":
+				if disablesErrexit(call.Args[1:]) {
+					errexit = false
+					sc.report(CheckDisableErrexit, call.Pos(), "set +e hides failing commands, handle errors explicitly instead")
+				} else if enablesErrexit(call.Args[1:]) {
+					errexit = true
+				}
+			case "cd":
+				if !errexit && !handled[n] && !n.Negated {
+					sc.report(CheckUncheckedCd, call.Pos(), "cd without set -e or error handling, the script continues in the wrong directory if it fails")
+				}
+			}
+			sc.checkWrites(n)
+		case *syntax.Lit:
+			if strings.Contains(n.Value, "/usr/local") {
+				sc.report(CheckUsrLocal, n.Pos(), "/usr/local is reserved for the local administrator, install to /usr instead")
+			}
+		}
+		return true
+	})
+}
+
+// checkPipe reports downloads that are piped into a shell.
+func (sc *scriptChecker) checkPipe(n *syntax.BinaryCmd) {
+	var cmds []string
+	var collect func(s *syntax.Stmt)
+	collect = func(s *syntax.Stmt) {
+		switch c := s.Cmd.(type) {
+		case *syntax.BinaryCmd:
+			if c.Op == syntax.Pipe || c.Op == syntax.PipeAll {
+				collect(c.X)
+				collect(c.Y)
+			}
+		case *syntax.CallExpr:
+			if len(c.Args) > 0 {
+				cmds = append(cmds, path.Base(c.Args[0].Lit()))
+			}
+		}
+	}
+	collect(n.X)
+
+	downloaded := false
+	for _, cmd := range cmds {
+		if cmd == "curl" || cmd == "wget" {
+			downloaded = true
+		}
+	}
+	if !downloaded {
+		return
+	}
+
+	if c, ok := n.Y.Cmd.(*syntax.CallExpr); ok && len(c.Args) > 0 {
+		switch shell := path.Base(c.Args[0].Lit()); shell {
+		case "sh", "bash", "ash", "dash", "zsh", "ksh":
+			sc.report(CheckCurlPipeShell, n.OpPos, "downloaded script is piped into %s, fetch sources with a pinned checksum instead", shell)
+		}
+	}
+}
+
+// checkWrites reports literal absolute paths written to by a statement that
+// are outside of the workspace, where the package contents are assembled.
+func (sc *scriptChecker) checkWrites(s *syntax.Stmt) {
+	var targets []*syntax.Word
+
+	for _, r := range s.Redirs {
+		switch r.Op {
+		case syntax.RdrOut, syntax.AppOut, syntax.ClbOut, syntax.RdrAll, syntax.AppAll:
+			targets = append(targets, r.Word)
+		}
+	}
+
+	if call, ok := s.Cmd.(*syntax.CallExpr); ok && len(call.Args) > 0 {
+		var operands []*syntax.Word
+		for _, w := range call.Args[1:] {
+			if !strings.HasPrefix(w.Lit(), "-") {
+				operands = append(operands, w)
+			}
+		}
+
+		switch path.Base(call.Args[0].Lit()) {
+		case "mkdir", "touch", "tee":
+			targets = append(targets, operands...)
+		case "install":
+			if hasFlag(call.Args[1:], 'd') {
+				targets = append(targets, operands...)
+			} else if len(operands) > 1 {
+				targets = append(targets, operands[len(operands)-1])
+			}
+		case "cp", "mv", "ln":
+			if len(operands) > 1 {
+				targets = append(targets, operands[len(operands)-1])
+			}
+		}
+	}
+
+	for _, w := range targets {
+		p := literalPrefix(w)
+		if !strings.HasPrefix(p, "/") || writable(p) {
+			continue
+		}
+		sc.report(CheckWriteOutsideDestdir, w.Pos(), "writes to %s, outside of the workspace; install into ${{targets.contextdir}} instead", p)
+	}
+}
+
+// literalPrefix returns the leading part of a word that is known before
+// expansion.
+func literalPrefix(w *syntax.Word) string {
+	var b strings.Builder
+	for _, part := range w.Parts {
+		switch p := part.(type) {
+		case *syntax.Lit:
+			b.WriteString(p.Value)
+		case *syntax.SglQuoted:
+			b.WriteString(p.Value)
+		case *syntax.DblQuoted:
+			for _, dp := range p.Parts {
+				lit, ok := dp.(*syntax.Lit)
+				if !ok {
+					return b.String()
+				}
+				b.WriteString(lit.Value)
+			}
+		default:
+			return b.String()
+		}
+	}
+	return b.String()
+}
+
+func writable(p string) bool {
+	p = path.Clean(p)
+	for _, prefix := range writablePrefixes {
+		if p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFlag reports whether any of the short options in args is flag.
+func hasFlag(args []*syntax.Word, flag rune) bool {
+	for _, w := range args {
+		lit := w.Lit()
+		if strings.HasPrefix(lit, "-") && !strings.HasPrefix(lit, "--") && strings.ContainsRune(lit[1:], flag) {
+			return true
+		}
+	}
+	return false
+}
+
+func disablesErrexit(args []*syntax.Word) bool {
+	for i, w := range args {
+		lit := w.Lit()
+		if lit == "+o" && i+1 < len(args) && args[i+1].Lit() == "errexit" {
+			return true
+		}
+		if strings.HasPrefix(lit, "+") && lit != "+o" && strings.ContainsRune(lit, 'e') {
+			return true
+		}
+	}
+	return false
+}
+
+func enablesErrexit(args []*syntax.Word) bool {
+	for i, w := range args {
+		lit := w.Lit()
+		if lit == "-o" && i+1 < len(args) && args[i+1].Lit() == "errexit" {
+			return true
+		}
+		if strings.HasPrefix(lit, "-") && lit != "-o" && !strings.HasPrefix(lit, "--") && strings.ContainsRune(lit, 'e') {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apko_types "chainguard.dev/apko/pkg/build/types"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+func TestCheckScripts(t *testing.T) {
+	ctx := context.Background()
+
+	pipelineDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(pipelineDir, "configure.yaml"), []byte(`inputs:
+  opts:
+    default: --enable-foo --enable-bar
+runs: |
+  ./configure ${{inputs.opts}}
+  ./configure "${{inputs.opts}}"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	configFile := filepath.Join(t.TempDir(), "check.yaml")
+	if err := os.WriteFile(configFile, []byte(`package:
+  name: check
+  version: 1.0.0
+  epoch: 0
+pipeline:
+  - uses: configure
+  - runs: |
+      set +e
+      cd src
+      cd src || exit 1
+      make install PREFIX=/usr/local
+      curl -sL https://example.com/install.sh | sh
+      cp foo /etc/foo
+      mkdir -p ${{targets.destdir}}/usr/bin
+      echo ok > /tmp/ok
+  - runs: cd src
+  - uses: go/build
+    with:
+      packages: ./cmd/foo ./cmd/bar
+      output: foo
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.ParseConfiguration(ctx, configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &Build{
+		Configuration: cfg,
+		Arch:          apko_types.ParseArchitecture("x86_64"),
+		PipelineDirs:  []string{pipelineDir},
+		CheckScripts:  true,
+	}
+	if err := b.Compile(ctx); err != nil {
+		t.Fatalf("compiling: %v", err)
+	}
+
+	type finding struct {
+		check string
+		pos   string
+	}
+	want := []finding{
+		{CheckUnquotedSubstitution, filepath.Join(pipelineDir, "configure.yaml") + ":5"},
+		{CheckDisableErrexit, configFile + ":8"},
+		{CheckUncheckedCd, configFile + ":9"},
+		{CheckUsrLocal, configFile + ":11"},
+		{CheckCurlPipeShell, configFile + ":12"},
+		{CheckWriteOutsideDestdir, configFile + ":13"},
+	}
+
+	var got []finding
+	for _, f := range b.ScriptFindings {
+		got = append(got, finding{f.Check, f.Position.String()})
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("want findings %v, got %v", want, b.ScriptFindings)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("finding %d: want %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestCheckScriptWithoutPosition(t *testing.T) {
+	p := &config.Pipeline{}
+	findings := checkScript(p, "mkdir /opt/foo", "mkdir /opt/foo", nil)
+	if len(findings) != 1 || findings[0].Check != CheckWriteOutsideDestdir {
+		t.Fatalf("want one %s finding, got %v", CheckWriteOutsideDestdir, findings)
+	}
+}
@@ -52,10 +52,18 @@ func (pc *PackageBuild) generateSLSA() ([]byte, error) {
 		return nil, err
 	}
 
+	// Remote pipelines are fetched from outside of the build configuration, so
+	// record them as resolved dependencies of the build.
+	resolvedDependencies := make([]*intoto.ResourceDescriptor, 0, len(pc.Build.RemotePipelines))
+	for _, rp := range pc.Build.RemotePipelines {
+		resolvedDependencies = append(resolvedDependencies, rp.resourceDescriptor())
+	}
+
 	predicate := &provenancev1.Provenance{
 		BuildDefinition: &provenancev1.BuildDefinition{
-			BuildType:          melangeBuildType,
-			ExternalParameters: externalParameters,
+			BuildType:            melangeBuildType,
+			ExternalParameters:   externalParameters,
+			ResolvedDependencies: resolvedDependencies,
 		},
 		RunDetails: &provenancev1.RunDetails{
 			Builder: slsaBuilder,
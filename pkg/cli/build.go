@@ -35,6 +35,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"chainguard.dev/melange/pkg/build"
+	"chainguard.dev/melange/pkg/config"
 	"chainguard.dev/melange/pkg/container"
 	"chainguard.dev/melange/pkg/container/docker"
 	"chainguard.dev/melange/pkg/linter"
@@ -59,6 +60,12 @@ func buildCmd() *cobra.Command {
 	var extraKeys []string
 	var extraRepos []string
 	var dependencyLog string
+	var explainDependencies bool
+	var allowFileConflicts bool
+	var lintBaseline string
+	var lintersDir string
+	var lintAgainst string
+	var lintSARIF bool
 	var envFile string
 	var varsFile string
 	var purlNamespace string
@@ -81,6 +88,7 @@ func buildCmd() *cobra.Command {
 	var configFileGitRepoURL string
 	var configFileLicense string
 	var generateProvenance bool
+	var checkScripts bool
 
 	var traceFile string
 
@@ -94,6 +102,12 @@ func buildCmd() *cobra.Command {
 			ctx := cmd.Context()
 			log := clog.FromContext(ctx)
 
+			if lintersDir != "" {
+				if err := linter.RegisterExternalLinters(lintersDir); err != nil {
+					return err
+				}
+			}
+
 			var buildConfigFilePath string
 			if len(args) > 0 {
 				buildConfigFilePath = args[0] // e.g. "crane.yaml"
@@ -167,6 +181,11 @@ func buildCmd() *cobra.Command {
 				build.WithExtraRepos(extraRepos),
 				build.WithExtraPackages(extraPackages),
 				build.WithDependencyLog(dependencyLog),
+				build.WithExplainDependencies(explainDependencies),
+				build.WithAllowFileConflicts(allowFileConflicts),
+				build.WithLintBaseline(lintBaseline),
+				build.WithLintSARIF(lintSARIF),
+				build.WithLintAgainst(lintAgainst),
 				build.WithStripOriginName(stripOriginName),
 				build.WithEnvFile(envFile),
 				build.WithVarsFile(varsFile),
@@ -192,6 +211,7 @@ func buildCmd() *cobra.Command {
 				build.WithConfigFileRepositoryURL(configFileGitRepoURL),
 				build.WithConfigFileLicense(configFileLicense),
 				build.WithGenerateProvenance(generateProvenance),
+				build.WithCheckScripts(checkScripts),
 			}
 
 			if len(args) > 0 {
@@ -236,6 +256,11 @@ func buildCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&stripOriginName, "strip-origin-name", false, "whether origin names should be stripped (for bootstrap)")
 	cmd.Flags().StringVar(&outDir, "out-dir", "./packages/", "directory where packages will be output")
 	cmd.Flags().StringVar(&dependencyLog, "dependency-log", "", "log dependencies to a specified file")
+	cmd.Flags().BoolVar(&explainDependencies, "explain-dependencies", false, "write why each generated dependency was generated next to the packages as JSON")
+	cmd.Flags().BoolVar(&allowFileConflicts, "allow-file-conflicts", false, "warn instead of failing when packages of the build install the same paths")
+	cmd.Flags().StringVar(&lintBaseline, "lint-baseline", "", "lint baseline file of accepted findings, which only fail the build if they are new")
+	cmd.Flags().BoolVar(&lintSARIF, "lint-sarif", false, "also write the lint findings as SARIF 2.1.0 to packages/{arch}/ directory")
+	cmd.Flags().StringVar(&lintAgainst, "lint-against", "", "repository of the previous versions of the packages, for the regressions linter to compare them to")
 	cmd.Flags().StringVar(&purlNamespace, "namespace", "unknown", "namespace to use in package URLs in SBOM (eg wolfi, alpine)")
 	cmd.Flags().StringSliceVar(&archstrs, "arch", nil, "architectures to build for (e.g., x86_64,ppc64le,arm64) -- default is all, unless specified in config")
 	cmd.Flags().StringVar(&libc, "override-host-triplet-libc-substitution-flavor", "gnu", "override the flavor of libc for ${{host.triplet.*}} substitutions (e.g. gnu,musl) -- default is gnu")
@@ -258,12 +283,14 @@ func buildCmd() *cobra.Command {
 	cmd.Flags().StringVar(&traceFile, "trace", "", "where to write trace output")
 	cmd.Flags().StringSliceVar(&lintRequire, "lint-require", linter.DefaultRequiredLinters(), "linters that must pass")
 	cmd.Flags().StringSliceVar(&lintWarn, "lint-warn", linter.DefaultWarnLinters(), "linters that will generate warnings")
+	cmd.Flags().StringVar(&lintersDir, "linters-dir", "", "directory of external linter executables, which can be enabled like the builtin linters")
 	cmd.Flags().BoolVar(&ignoreSignatures, "ignore-signatures", false, "ignore repository signature verification")
 	cmd.Flags().BoolVar(&cleanup, "cleanup", true, "when enabled, the temp dir used for the guest will be cleaned up after completion")
 	cmd.Flags().StringVar(&configFileGitCommit, "git-commit", "", "commit hash of the git repository containing the build config file (defaults to detecting HEAD)")
 	cmd.Flags().StringVar(&configFileGitRepoURL, "git-repo-url", "", "URL of the git repository containing the build config file (defaults to detecting from configured git remotes)")
 	cmd.Flags().StringVar(&configFileLicense, "license", "NOASSERTION", "license to use for the build config file itself")
 	cmd.Flags().BoolVar(&generateProvenance, "generate-provenance", false, "generate SLSA provenance for builds (included in a separate .attest.tar.gz file next to the APK)")
+	cmd.Flags().BoolVar(&checkScripts, "check-scripts", false, "check pipeline scripts for likely mistakes, and log them as warnings")
 
 	_ = cmd.Flags().Bool("fail-on-lint-warning", false, "DEPRECATED: DO NOT USE")
 	_ = cmd.Flags().MarkDeprecated("fail-on-lint-warning", "use --lint-require and --lint-warn instead")
@@ -339,7 +366,7 @@ func BuildCmd(ctx context.Context, archs []apko_types.Architecture, baseOpts ...
 			log.Warnf("skipping arch %s", arch)
 			continue
 		} else if err != nil {
-			return err
+			return config.FormatError(err)
 		}
 
 		defer bc.Close(ctx)
@@ -373,7 +400,7 @@ func BuildCmd(ctx context.Context, archs []apko_types.Architecture, baseOpts ...
 					bc.SummarizePaths(lctx)
 				}
 
-				return fmt.Errorf("failed to build package: %w", err)
+				return config.FormatError(fmt.Errorf("failed to build package: %w", err))
 			}
 			return nil
 		})
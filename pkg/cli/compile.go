@@ -29,6 +29,7 @@ import (
 	"go.opentelemetry.io/otel"
 
 	"chainguard.dev/melange/pkg/build"
+	"chainguard.dev/melange/pkg/config"
 )
 
 func compile() *cobra.Command {
@@ -48,6 +49,9 @@ func compile() *cobra.Command {
 	var extraKeys []string
 	var extraRepos []string
 	var dependencyLog string
+	var lintBaseline string
+	var lintSARIF bool
+	var lintAgainst string
 	var envFile string
 	var varsFile string
 	var purlNamespace string
@@ -67,6 +71,7 @@ func compile() *cobra.Command {
 	var configFileGitRepoURL string
 	var configFileLicense string
 	var generateProvenance bool
+	var checkScripts bool
 
 	cmd := &cobra.Command{
 		Use:     "compile",
@@ -122,6 +127,9 @@ func compile() *cobra.Command {
 				build.WithExtraRepos(extraRepos),
 				build.WithExtraPackages(extraPackages),
 				build.WithDependencyLog(dependencyLog),
+				build.WithLintBaseline(lintBaseline),
+				build.WithLintSARIF(lintSARIF),
+				build.WithLintAgainst(lintAgainst),
 				build.WithStripOriginName(stripOriginName),
 				build.WithEnvFile(envFile),
 				build.WithVarsFile(varsFile),
@@ -139,6 +147,7 @@ func compile() *cobra.Command {
 				build.WithConfigFileRepositoryURL(configFileGitRepoURL),
 				build.WithConfigFileLicense(configFileLicense),
 				build.WithGenerateProvenance(generateProvenance),
+				build.WithCheckScripts(checkScripts),
 			}
 
 			if len(args) > 0 {
@@ -188,6 +197,9 @@ func compile() *cobra.Command {
 	cmd.Flags().BoolVar(&stripOriginName, "strip-origin-name", false, "whether origin names should be stripped (for bootstrap)")
 	cmd.Flags().StringVar(&outDir, "out-dir", "./packages/", "directory where packages will be output")
 	cmd.Flags().StringVar(&dependencyLog, "dependency-log", "", "log dependencies to a specified file")
+	cmd.Flags().StringVar(&lintBaseline, "lint-baseline", "", "lint baseline file of accepted findings, which only fail the build if they are new")
+	cmd.Flags().BoolVar(&lintSARIF, "lint-sarif", false, "also write the lint findings as SARIF 2.1.0 to packages/{arch}/ directory")
+	cmd.Flags().StringVar(&lintAgainst, "lint-against", "", "repository of the previous versions of the packages, for the regressions linter to compare them to")
 	cmd.Flags().StringVar(&purlNamespace, "namespace", "unknown", "namespace to use in package URLs in SBOM (eg wolfi, alpine)")
 	cmd.Flags().StringSliceVar(&buildOption, "build-option", []string{}, "build options to enable")
 	cmd.Flags().StringSliceVar(&logPolicy, "log-policy", []string{"builtin:stderr"}, "logging policy to use")
@@ -205,6 +217,7 @@ func compile() *cobra.Command {
 	cmd.Flags().StringVar(&memory, "memory", "", "default memory resources to use for builds")
 	cmd.Flags().DurationVar(&timeout, "timeout", 0, "default timeout for builds")
 	cmd.Flags().BoolVar(&generateProvenance, "generate-provenance", false, "generate SLSA provenance for builds (included in a separate .attest.tar.gz file next to the APK)")
+	cmd.Flags().BoolVar(&checkScripts, "check-scripts", false, "check pipeline scripts for likely mistakes, and fail if any are found")
 
 	cmd.Flags().StringVar(&configFileGitCommit, "git-commit", "", "commit hash of the git repository containing the build config file (defaults to detecting HEAD)")
 	cmd.Flags().StringVar(&configFileGitRepoURL, "git-repo-url", "", "URL of the git repository containing the build config file (defaults to detecting from configured git remotes)")
@@ -219,13 +232,20 @@ func CompileCmd(ctx context.Context, opts ...build.Option) error {
 
 	bc, err := build.New(ctx, opts...)
 	if err != nil {
-		return err
+		return config.FormatError(err)
 	}
 
 	defer bc.Close(ctx)
 
 	if err := bc.Compile(ctx); err != nil {
-		return fmt.Errorf("failed to compile %s: %w", bc.ConfigFile, err)
+		return config.FormatError(fmt.Errorf("failed to compile %s: %w", bc.ConfigFile, err))
+	}
+
+	if len(bc.ScriptFindings) > 0 {
+		for _, f := range bc.ScriptFindings {
+			fmt.Fprintln(os.Stderr, f)
+		}
+		return fmt.Errorf("found %d problem(s) in pipeline scripts", len(bc.ScriptFindings))
 	}
 
 	return json.NewEncoder(os.Stdout).Encode(bc.Configuration)
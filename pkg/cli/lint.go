@@ -16,6 +16,7 @@ package cli
 
 import (
 	"errors"
+	"fmt"
 	"runtime"
 	"sync"
 
@@ -31,11 +32,15 @@ func lint() *cobra.Command {
 	var lintRequire, lintWarn []string
 	var outDir string
 	var persistLintResults bool
+	var baselinePath, writeBaseline string
+	var sarifPath string
+	var lintersDir string
+	var against []string
 	cmd := &cobra.Command{
 		Use:     "lint",
 		Short:   "EXPERIMENTAL COMMAND - Lints an APK, checking for problems and errors",
 		Long:    `Lint is an EXPERIMENTAL COMMAND - Lints an APK file, checking for problems and errors.`,
-		Example: `  melange lint [--enable=foo[,bar]] [--disable=baz] [--persist-lint-results] [--out-dir=./output] foo.apk`,
+		Example: `  melange lint [--enable=foo[,bar]] [--disable=baz] [--persist-lint-results] [--out-dir=./output] [--baseline=lint-baseline.yaml] [--sarif=lint.sarif] [--against=foo-1.0-r0.apk] foo.apk`,
 		Args:    cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
@@ -43,6 +48,12 @@ func lint() *cobra.Command {
 			g.SetLimit(runtime.GOMAXPROCS(0))
 
 			log := clog.FromContext(ctx)
+			if lintersDir != "" {
+				if err := linter.RegisterExternalLinters(lintersDir); err != nil {
+					return err
+				}
+			}
+
 			log.Infof("Required checks: %v", lintRequire)
 			log.Infof("Warning checks: %v", lintWarn)
 
@@ -52,6 +63,36 @@ func lint() *cobra.Command {
 				outputDir = outDir
 			}
 
+			var baseline *linter.Baseline
+			var err error
+			switch {
+			case writeBaseline != "":
+				baseline, err = linter.NewBaselineRecorder(writeBaseline)
+			case baselinePath != "":
+				baseline, err = linter.LoadBaseline(baselinePath)
+			}
+			if err != nil {
+				return err
+			}
+			if baseline != nil {
+				ctx = linter.WithBaseline(ctx, baseline)
+			}
+
+			var sarif *linter.SARIFReport
+			if sarifPath != "" {
+				sarif = linter.NewSARIFReport()
+				ctx = linter.WithSARIF(ctx, sarif)
+			}
+
+			if len(against) > 0 {
+				prev, err := linter.PreviousFromAPKs(ctx, against)
+				if err != nil {
+					return err
+				}
+				defer prev.Close()
+				ctx = linter.WithPreviousPackages(ctx, prev)
+			}
+
 			errs := []error{}
 			var mu sync.Mutex
 			for _, pkg := range args {
@@ -70,14 +111,36 @@ func lint() *cobra.Command {
 			if err := g.Wait(); err != nil {
 				return err
 			}
+
+			if writeBaseline != "" {
+				if err := baseline.Write(writeBaseline); err != nil {
+					return fmt.Errorf("writing lint baseline: %w", err)
+				}
+				log.Infof("wrote lint baseline to %s, justify its new entries", writeBaseline)
+			} else if baseline != nil {
+				baseline.ReportStale(ctx)
+			}
+
+			if sarif != nil {
+				if err := sarif.Write(sarifPath); err != nil {
+					return err
+				}
+				log.Infof("wrote SARIF lint results to %s", sarifPath)
+			}
+
 			return errors.Join(errs...)
 		},
 	}
 
 	cmd.Flags().StringSliceVar(&lintRequire, "lint-require", linter.DefaultRequiredLinters(), "linters that must pass")
 	cmd.Flags().StringSliceVar(&lintWarn, "lint-warn", linter.DefaultWarnLinters(), "linters that will generate warnings")
+	cmd.Flags().StringVar(&lintersDir, "linters-dir", "", "directory of external linter executables, which can be enabled like the builtin linters")
 	cmd.Flags().BoolVar(&persistLintResults, "persist-lint-results", false, "persist lint results to JSON files in packages/{arch}/ directory")
 	cmd.Flags().StringVar(&outDir, "out-dir", "packages", "directory where lint results JSON files will be saved (requires --persist-lint-results)")
+	cmd.Flags().StringVar(&baselinePath, "baseline", "", "lint baseline file of accepted findings, which only fail if they are new")
+	cmd.Flags().StringSliceVar(&against, "against", []string{}, "previous versions of the packages, as APK files or URLs, for the regressions linter to compare them to")
+	cmd.Flags().StringVar(&sarifPath, "sarif", "", "write the lint findings of all packages to this file as SARIF 2.1.0")
+	cmd.Flags().StringVar(&writeBaseline, "write-baseline", "", "write the findings to this lint baseline file instead of failing, keeping the justifications already in it")
 
 	_ = cmd.Flags().Bool("fail-on-lint-warning", false, "DEPRECATED: DO NOT USE")
 	_ = cmd.Flags().MarkDeprecated("fail-on-lint-warning", "use --lint-require and --lint-warn instead")
@@ -0,0 +1,240 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"chainguard.dev/melange/pkg/build"
+	"chainguard.dev/melange/pkg/config"
+)
+
+func pipelines() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pipelines",
+		Short: "List, describe and validate pipelines",
+		Long:  `List, describe and validate the builtin pipelines and pipelines in pipeline directories.`,
+	}
+
+	cmd.AddCommand(pipelinesList())
+	cmd.AddCommand(pipelinesDescribe())
+	cmd.AddCommand(pipelinesValidate())
+
+	return cmd
+}
+
+func pipelinesList() *cobra.Command {
+	var pipelineDirs []string
+	var outputJSON bool
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the pipelines available to uses",
+		Example: `  melange pipelines list [--pipeline-dir=./pipelines]`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return PipelinesListCmd(cmd.OutOrStdout(), pipelineDirs, outputJSON)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&pipelineDirs, "pipeline-dir", []string{}, "directories used to extend defined built-in pipelines")
+	cmd.Flags().BoolVar(&outputJSON, "json", false, "print the pipelines as JSON")
+
+	return cmd
+}
+
+// PipelinesListCmd prints every pipeline that can be referenced with `uses`.
+func PipelinesListCmd(w io.Writer, pipelineDirs []string, outputJSON bool) error {
+	infos, err := build.ListPipelines(pipelineDirs)
+	if err != nil {
+		return err
+	}
+
+	if outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(infos)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSOURCE\tDESCRIPTION")
+	for _, info := range infos {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", info.Name, info.Source, info.Pipeline.Name)
+	}
+
+	return tw.Flush()
+}
+
+func pipelinesDescribe() *cobra.Command {
+	var pipelineDirs []string
+	var outputJSON bool
+
+	cmd := &cobra.Command{
+		Use:     "describe",
+		Short:   "Describe the inputs, needs and steps of a pipeline",
+		Example: `  melange pipelines describe go/build`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return PipelinesDescribeCmd(cmd.OutOrStdout(), pipelineDirs, args[0], outputJSON)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&pipelineDirs, "pipeline-dir", []string{}, "directories used to extend defined built-in pipelines")
+	cmd.Flags().BoolVar(&outputJSON, "json", false, "print the pipeline as JSON")
+
+	return cmd
+}
+
+// PipelinesDescribeCmd prints the definition of the pipeline `uses: name`
+// resolves to.
+func PipelinesDescribeCmd(w io.Writer, pipelineDirs []string, name string, outputJSON bool) error {
+	info, err := build.DescribePipeline(pipelineDirs, name)
+	if err != nil {
+		return err
+	}
+
+	if outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+
+	p := info.Pipeline
+	fmt.Fprintf(w, "Name:        %s\n", info.Name)
+	fmt.Fprintf(w, "Source:      %s\n", info.Source)
+	if p.Name != "" {
+		fmt.Fprintf(w, "Description: %s\n", p.Name)
+	}
+
+	if len(p.Inputs) > 0 {
+		fmt.Fprintln(w, "\nInputs:")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  NAME\tREQUIRED\tDEFAULT\tDESCRIPTION")
+		for _, k := range slices.Sorted(maps.Keys(p.Inputs)) {
+			in := p.Inputs[k]
+			fmt.Fprintf(tw, "  %s\t%t\t%s\t%s\n", k, in.Required, strings.TrimSpace(in.Default), strings.Join(strings.Fields(in.Description), " "))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if p.Needs != nil && len(p.Needs.Packages) > 0 {
+		fmt.Fprintln(w, "\nNeeds:")
+		for _, pkg := range p.Needs.Packages {
+			fmt.Fprintf(w, "  - %s\n", pkg)
+		}
+	}
+
+	fmt.Fprintln(w, "\nSteps:")
+	if p.Runs != "" {
+		describeStep(w, config.Pipeline{Runs: p.Runs, If: p.If}, 1)
+	}
+	for _, step := range p.Pipeline {
+		describeStep(w, step, 1)
+	}
+
+	return nil
+}
+
+// describeStep prints a one line summary of a pipeline step and its children.
+func describeStep(w io.Writer, p config.Pipeline, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	var summary string
+	switch {
+	case p.Name != "":
+		summary = p.Name
+	case p.Uses != "":
+		summary = "uses: " + p.Uses
+	case p.Runs != "":
+		summary = "runs: " + firstCommand(p.Runs)
+	default:
+		summary = "(unnamed)"
+	}
+	if p.If != "" {
+		summary += fmt.Sprintf(" (if: %s)", p.If)
+	}
+
+	fmt.Fprintf(w, "%s- %s\n", indent, summary)
+	for _, child := range p.Pipeline {
+		describeStep(w, child, depth+1)
+	}
+}
+
+func pipelinesValidate() *cobra.Command {
+	var pipelineDirs []string
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the pipeline definitions in a directory",
+		Long: `Validate the pipeline definitions in a directory.
+
+Every pipeline is compiled, and references to undefined inputs, inputs that
+are never used and shell syntax errors are reported.`,
+		Example: `  melange pipelines validate ./pipelines`,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return PipelinesValidateCmd(cmd.Context(), cmd.OutOrStdout(), args, pipelineDirs)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&pipelineDirs, "pipeline-dir", []string{}, "directories used to resolve nested uses, in addition to the validated directory")
+
+	return cmd
+}
+
+// PipelinesValidateCmd validates the pipelines in each of dirs, printing any
+// problem found, and returns an error if there were any.
+func PipelinesValidateCmd(ctx context.Context, w io.Writer, dirs, pipelineDirs []string) error {
+	count := 0
+	for _, dir := range dirs {
+		problems, err := build.ValidatePipelines(ctx, dir, pipelineDirs)
+		if err != nil {
+			return fmt.Errorf("validating pipelines in %s: %w", dir, err)
+		}
+
+		for _, p := range problems {
+			fmt.Fprintf(w, "%s: %s\n", dir, p)
+		}
+		count += len(problems)
+	}
+
+	if count > 0 {
+		return fmt.Errorf("found %d problem(s) in pipelines", count)
+	}
+
+	return nil
+}
+
+// firstCommand returns the first line of a script that isn't blank or a comment.
+func firstCommand(runs string) string {
+	for line := range strings.Lines(runs) {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+	return ""
+}
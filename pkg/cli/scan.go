@@ -18,10 +18,15 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
 	"net/http"
 	"os"
+	"path/filepath"
+	"runtime"
 	"slices"
 	"sort"
 	"strconv"
@@ -30,6 +35,8 @@ import (
 
 	"chainguard.dev/apko/pkg/apk/apk"
 	"chainguard.dev/apko/pkg/apk/expandapk"
+	apkofs "chainguard.dev/apko/pkg/apk/fs"
+	apko_types "chainguard.dev/apko/pkg/build/types"
 	"github.com/chainguard-dev/clog"
 	"github.com/spf13/cobra"
 	"go.opentelemetry.io/otel"
@@ -46,19 +53,47 @@ type scanConfig struct {
 	archs    []string
 	diff     bool
 	comments bool
+	explain  bool
+
+	// The unpacked package tree to scan instead of an APK, and the name and
+	// version of the package it is for.
+	dir     string
+	name    string
+	version string
+
+	// The number of configurations to scan at once, and the format of the
+	// report when scanning more than one.
+	jobs   int
+	format string
 
 	purlNamespace string
+
+	// The resolvers for the repository index of each architecture, if any.
+	resolvers map[string]*apk.PkgResolver
 }
 
 func scan() *cobra.Command {
 	sc := scanConfig{}
 
 	cmd := &cobra.Command{
-		Use:     "scan",
-		Short:   "Scan an existing APK to regenerate .PKGINFO",
-		Example: `melange scan bash.yaml`,
-		Args:    cobra.ExactArgs(1),
+		Use:   "scan",
+		Short: "Scan an existing APK to regenerate .PKGINFO",
+		Example: `melange scan bash.yaml
+melange scan --dir ./destdir --name foo
+melange scan --format json ./os`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if sc.dir != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if sc.dir != "" {
+				return scanDirCmd(cmd.Context(), os.Stdout, &sc)
+			}
+			if len(args) > 1 || isDir(args[0]) {
+				return scanBatchCmd(cmd.Context(), args, &sc)
+			}
 			return scanCmd(cmd.Context(), args[0], &sc)
 		},
 	}
@@ -71,32 +106,137 @@ func scan() *cobra.Command {
 	cmd.Flags().StringSliceVar(&sc.archs, "arch", []string{}, "architectures to scan (default is x86_64)")
 	cmd.Flags().BoolVar(&sc.diff, "diff", false, "show diff output")
 	cmd.Flags().BoolVar(&sc.comments, "comments", false, "include comments in .PKGINFO diff")
+	cmd.Flags().BoolVar(&sc.explain, "explain", false, "print why each generated dependency was generated instead of .PKGINFO")
+
+	cmd.Flags().StringVar(&sc.dir, "dir", "", "scan the unpacked package tree in this directory instead of an APK")
+	cmd.Flags().StringVar(&sc.name, "name", "", "name of the package being scanned with --dir (default is the base name of the directory)")
+	cmd.Flags().StringVar(&sc.version, "version", "0-r0", "version of the package being scanned with --dir")
+
+	cmd.Flags().IntVar(&sc.jobs, "jobs", runtime.GOMAXPROCS(0), "number of configs to scan in parallel when scanning more than one")
+	cmd.Flags().StringVar(&sc.format, "format", "text", "format of the report when scanning more than one config: 'text' or 'json'")
 
 	cmd.Flags().StringVar(&sc.purlNamespace, "namespace", "unknown", "namespace to use in package URLs in SBOM (eg wolfi, alpine)")
 
 	return cmd
 }
 
-// TODO: It would be cool if there was a way this could take just a directory.
+// scanResult is the .PKGINFO regenerated for one package of a configuration,
+// along with the one from its APK.
+type scanResult struct {
+	arch         string
+	pkgName      string
+	oldName      string
+	old          []byte
+	generated    []byte
+	explanations sca.Explanations
+}
+
 func scanCmd(ctx context.Context, file string, sc *scanConfig) error {
 	ctx, span := otel.Tracer("melange").Start(ctx, "scan")
 	defer span.End()
 
-	log := clog.FromContext(ctx)
+	if err := sc.loadResolvers(ctx); err != nil {
+		return err
+	}
+
+	results, err := scanFile(ctx, file, sc)
+	if err != nil {
+		return err
+	}
 
 	sawDiff := false
+	for _, res := range results {
+		switch {
+		case sc.explain:
+			if sc.pkg == "" || sc.pkg == res.pkgName {
+				printExplanations(os.Stdout, res.pkgName, res.explanations)
+			}
+		case sc.diff:
+			if diff := Diff(res.oldName, res.old, file, res.generated, sc.comments); diff != nil {
+				sawDiff = true
+				os.Stdout.Write(diff)
+			}
+		case sc.pkg == "" || sc.pkg == res.pkgName:
+			os.Stdout.Write(res.generated)
+		}
+	}
+
+	if sawDiff {
+		return fmt.Errorf("saw diff for %s", file)
+	}
+
+	return nil
+}
+
+func (sc *scanConfig) scanArchs() []string {
+	if len(sc.archs) == 0 {
+		return []string{"x86_64"}
+	}
+	return sc.archs
+}
+
+// loadResolvers loads the APKINDEX of the repository for each architecture
+// being scanned, so dependencies on the packages in it can be generated.
+// Repositories without an index are scanned without them.
+func (sc *scanConfig) loadResolvers(ctx context.Context) error {
+	log := clog.FromContext(ctx)
+
+	sc.resolvers = map[string]*apk.PkgResolver{}
+	for _, arch := range sc.scanArchs() {
+		u := fmt.Sprintf("%s/%s/APKINDEX.tar.gz", sc.repo, arch)
+
+		var r io.ReadCloser
+		if strings.HasPrefix(u, "http") {
+			// #nosec G107 - URL is constructed from trusted configuration values
+			resp, err := http.Get(u)
+			if err != nil {
+				return fmt.Errorf("get %s: %w", u, err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				resp.Body.Close()
+				log.Debugf("Get %s: %d, scanning without an index", u, resp.StatusCode)
+				continue
+			}
+			r = resp.Body
+		} else {
+			f, err := os.Open(u)
+			if errors.Is(err, fs.ErrNotExist) {
+				log.Debugf("no %s, scanning without an index", u)
+				continue
+			} else if err != nil {
+				return err
+			}
+			r = f
+		}
 
-	archs := sc.archs
-	if len(archs) == 0 {
-		archs = []string{"x86_64"}
+		index, err := apk.IndexFromArchive(r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", u, err)
+		}
+
+		repo := apk.Repository{URI: fmt.Sprintf("%s/%s", sc.repo, arch)}
+		// Packages of a named repository only resolve when pinned to it.
+		sc.resolvers[arch] = apk.NewPkgResolver(ctx, []apk.NamedIndex{
+			apk.NewNamedRepositoryWithIndex("", repo.WithIndex(index)),
+		})
 	}
 
+	return nil
+}
+
+// scanFile regenerates the .PKGINFO of each package of the configuration in
+// file from its APK in the repository, for each architecture being scanned.
+func scanFile(ctx context.Context, file string, sc *scanConfig) ([]scanResult, error) {
+	log := clog.FromContext(ctx)
+
 	cfg, err := config.ParseConfiguration(ctx, file)
 	if err != nil {
-		return fmt.Errorf("parse config: %w", err)
+		return nil, fmt.Errorf("parse config: %w", err)
 	}
 
-	for _, arch := range archs {
+	var results []scanResult
+	for _, arch := range sc.scanArchs() {
 		exps := map[string]*expandapk.APKExpanded{}
 
 		pkg := cfg.Package
@@ -108,21 +248,21 @@ func scanCmd(ctx context.Context, file string, sc *scanConfig) error {
 			// #nosec G107 - URL is constructed from trusted configuration values
 			resp, err := http.Get(u)
 			if err != nil {
-				return fmt.Errorf("get %s: %w", u, err)
+				return nil, fmt.Errorf("get %s: %w", u, err)
 			}
 			defer resp.Body.Close()
 			r = resp.Body
 		} else {
 			f, err := os.Open(u)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			defer f.Close()
 			r = f
 		}
 		exp, err := expandapk.ExpandApk(ctx, r, "")
 		if err != nil {
-			return err
+			return nil, err
 		}
 		defer exp.Close()
 
@@ -130,29 +270,29 @@ func scanCmd(ctx context.Context, file string, sc *scanConfig) error {
 
 		f, err := exp.ControlFS.Open(".PKGINFO")
 		if err != nil {
-			return fmt.Errorf("opening .PKGINFO in %s: %w", exp.ControlFile, err)
+			return nil, fmt.Errorf("opening .PKGINFO in %s: %w", exp.ControlFile, err)
 		}
 		defer f.Close()
 
 		b, err := io.ReadAll(f)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		info, err := parsePkgInfo(bytes.NewReader(b))
 		if err != nil {
-			return fmt.Errorf("parsing .PKGINFO: %w", err)
+			return nil, fmt.Errorf("parsing .PKGINFO: %w", err)
 		}
 
 		pkg.Commit = info.commit
 
 		installedSize, err := strconv.ParseInt(info.size, 10, 64)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		dir, err := os.MkdirTemp("", info.pkgname)
 		if err != nil {
-			return fmt.Errorf("mkdirtemp: %w", err)
+			return nil, fmt.Errorf("mkdirtemp: %w", err)
 		}
 		defer os.RemoveAll(dir)
 
@@ -162,6 +302,10 @@ func scanCmd(ctx context.Context, file string, sc *scanConfig) error {
 			Configuration:   cfg,
 			Namespace:       sc.purlNamespace,
 		}
+		// Resolvers cache what they resolve, so each scan needs its own.
+		if resolver := sc.resolvers[arch]; resolver != nil {
+			bb.PkgResolver = resolver.Clone()
+		}
 
 		pb := build.PackageBuild{
 			Build:         bb,
@@ -182,7 +326,7 @@ func scanCmd(ctx context.Context, file string, sc *scanConfig) error {
 		if info.builddate != "" {
 			sec, err := strconv.ParseInt(info.builddate, 10, 64)
 			if err != nil {
-				return fmt.Errorf("parsing %q as timestamp: %w", info.builddate, err)
+				return nil, fmt.Errorf("parsing %q as timestamp: %w", info.builddate, err)
 			}
 			pb.Build.SourceDateEpoch = time.Unix(sec, 0)
 		}
@@ -199,7 +343,7 @@ func scanCmd(ctx context.Context, file string, sc *scanConfig) error {
 				// #nosec G107 - URL is constructed from trusted configuration values
 				resp, err := http.Get(u)
 				if err != nil {
-					return fmt.Errorf("get %s: %w", u, err)
+					return nil, fmt.Errorf("get %s: %w", u, err)
 				}
 				defer resp.Body.Close()
 				if resp.StatusCode != http.StatusOK {
@@ -210,7 +354,7 @@ func scanCmd(ctx context.Context, file string, sc *scanConfig) error {
 			} else {
 				f, err := os.Open(u)
 				if err != nil {
-					return err
+					return nil, err
 				}
 				defer f.Close()
 				r = f
@@ -218,7 +362,7 @@ func scanCmd(ctx context.Context, file string, sc *scanConfig) error {
 
 			exp, err := expandapk.ExpandApk(ctx, r, "")
 			if err != nil {
-				return err
+				return nil, err
 			}
 			defer exp.Close()
 
@@ -226,17 +370,17 @@ func scanCmd(ctx context.Context, file string, sc *scanConfig) error {
 
 			f, err := exp.ControlFS.Open(".PKGINFO")
 			if err != nil {
-				return fmt.Errorf("opening .PKGINFO in %s: %w", exp.ControlFile, err)
+				return nil, fmt.Errorf("opening .PKGINFO in %s: %w", exp.ControlFile, err)
 			}
 			defer f.Close()
 
 			b, err := io.ReadAll(f)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			info, err := parsePkgInfo(bytes.NewReader(b))
 			if err != nil {
-				return fmt.Errorf("parsing .PKGINFO: %w", err)
+				return nil, fmt.Errorf("parsing .PKGINFO: %w", err)
 			}
 
 			infos[subpkg.Name] = info
@@ -246,7 +390,7 @@ func scanCmd(ctx context.Context, file string, sc *scanConfig) error {
 
 			installedSize, err := strconv.ParseInt(info.size, 10, 64)
 			if err != nil {
-				return err
+				return nil, err
 			}
 
 			pb := build.PackageBuild{
@@ -270,7 +414,7 @@ func scanCmd(ctx context.Context, file string, sc *scanConfig) error {
 			if info.builddate != "" {
 				sec, err := strconv.ParseInt(info.builddate, 10, 64)
 				if err != nil {
-					return fmt.Errorf("parsing %q as timestamp: %w", info.builddate, err)
+					return nil, fmt.Errorf("parsing %q as timestamp: %w", info.builddate, err)
 				}
 				pb.Build.SourceDateEpoch = time.Unix(sec, 0)
 			}
@@ -289,28 +433,22 @@ func scanCmd(ctx context.Context, file string, sc *scanConfig) error {
 			}
 
 			if err := pb.GenerateDependencies(ctx, hdl); err != nil {
-				return err
+				return nil, err
 			}
 
 			var buf bytes.Buffer
 			if err := pb.GenerateControlData(&buf); err != nil {
-				return fmt.Errorf("unable to process control template: %w", err)
+				return nil, fmt.Errorf("unable to process control template: %w", err)
 			}
 
-			generated := buf.Bytes()
-
-			if sc.diff {
-				b := controls[subpkg.Name]
-				old := fmt.Sprintf("%s-%s.apk", info.pkgname, info.pkgver)
-
-				diff := Diff(old, b, file, generated, sc.comments)
-				if diff != nil {
-					sawDiff = true
-					os.Stdout.Write(diff)
-				}
-			} else if sc.pkg == "" || sc.pkg == subpkg.Name {
-				os.Stdout.Write(generated)
-			}
+			results = append(results, scanResult{
+				arch:         arch,
+				pkgName:      subpkg.Name,
+				oldName:      fmt.Sprintf("%s-%s.apk", info.pkgname, info.pkgver),
+				old:          controls[subpkg.Name],
+				generated:    buf.Bytes(),
+				explanations: pb.Explanations,
+			})
 		}
 
 		hdl := &scaImpl{
@@ -319,35 +457,132 @@ func scanCmd(ctx context.Context, file string, sc *scanConfig) error {
 		}
 
 		if err := pb.GenerateDependencies(ctx, hdl); err != nil {
-			return err
+			return nil, err
 		}
 
 		var buf bytes.Buffer
 		if err := pb.GenerateControlData(&buf); err != nil {
-			return fmt.Errorf("unable to process control template: %w", err)
+			return nil, fmt.Errorf("unable to process control template: %w", err)
 		}
 
-		generated := buf.Bytes()
+		results = append(results, scanResult{
+			arch:         arch,
+			pkgName:      pkg.Name,
+			oldName:      fmt.Sprintf("%s-%s.apk", info.pkgname, info.pkgver),
+			old:          b,
+			generated:    buf.Bytes(),
+			explanations: pb.Explanations,
+		})
+	}
 
-		if sc.diff {
-			old := fmt.Sprintf("%s-%s.apk", info.pkgname, info.pkgver)
-			diff := Diff(old, b, file, generated, sc.comments)
-			if diff != nil {
-				sawDiff = true
-				os.Stdout.Write(diff)
-			}
-		} else if sc.pkg == "" || sc.pkg == pkg.Name {
-			os.Stdout.Write(generated)
+	return results, nil
+}
+
+// scanDirCmd prints the dependencies generated for an unpacked package tree,
+// such as the DESTDIR of a local build, without a configuration or an APK.
+func scanDirCmd(ctx context.Context, w io.Writer, sc *scanConfig) error {
+	ctx, span := otel.Tracer("melange").Start(ctx, "scan")
+	defer span.End()
+
+	fi, err := os.Stat(sc.dir)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory", sc.dir)
+	}
+
+	name := sc.name
+	if name == "" {
+		abs, err := filepath.Abs(sc.dir)
+		if err != nil {
+			return err
 		}
+		name = filepath.Base(abs)
 	}
 
-	if sawDiff {
-		return fmt.Errorf("saw diff for %s", file)
+	fsys, ok := apkofs.DirFS(ctx, sc.dir).(sca.SCAFS)
+	if !ok {
+		return fmt.Errorf("SCAFS not implemented")
+	}
+
+	arch := "x86_64"
+	if len(sc.archs) > 0 {
+		arch = apko_types.ParseArchitecture(sc.archs[0]).ToAPK()
+	}
+
+	hdl := &dirImpl{
+		name:    name,
+		version: sc.version,
+		arch:    arch,
+		fsys:    fsys,
+	}
+
+	pb := build.PackageBuild{
+		Build:       &build.Build{},
+		PackageName: name,
+		OriginName:  name,
+		Arch:        arch,
+	}
+	if err := pb.GenerateDependencies(ctx, hdl); err != nil {
+		return err
+	}
+
+	if sc.explain {
+		printExplanations(w, name, pb.Explanations)
+	} else {
+		printDependencies(w, name, pb.Dependencies)
 	}
 
 	return nil
 }
 
+// printDependencies prints the dependencies of a package like they appear in
+// .PKGINFO.
+func printDependencies(w io.Writer, pkgName string, deps config.Dependencies) {
+	fmt.Fprintf(w, "# %s\n", pkgName)
+	for _, dep := range deps.Runtime {
+		fmt.Fprintf(w, "depend = %s\n", dep)
+	}
+	for _, prov := range deps.Provides {
+		fmt.Fprintf(w, "provides = %s\n", prov)
+	}
+	for _, vendored := range deps.Vendored {
+		fmt.Fprintf(w, "# vendored = %s\n", vendored)
+	}
+}
+
+// printExplanations prints the generated dependencies of a package like they
+// appear in .PKGINFO, each followed by the generators, files and reasons that
+// caused it.
+func printExplanations(w io.Writer, pkgName string, explanations sca.Explanations) {
+	fmt.Fprintf(w, "# %s\n", pkgName)
+
+	for _, kind := range []struct{ kind, key string }{
+		{sca.KindRuntime, "depend"},
+		{sca.KindProvides, "provides"},
+		{sca.KindVendored, "# vendored"},
+	} {
+		byEntry := map[string][]sca.Explanation{}
+		for _, e := range explanations {
+			if e.Kind == kind.kind {
+				byEntry[e.Entry] = append(byEntry[e.Entry], e)
+			}
+		}
+
+		for _, entry := range slices.Sorted(maps.Keys(byEntry)) {
+			fmt.Fprintf(w, "%s = %s\n", kind.key, entry)
+			for _, e := range byEntry[entry] {
+				path := e.Path
+				if path == "" {
+					path = "-"
+				}
+				fmt.Fprintf(w, "\t%s: %s: %s\n", e.Generator, path, e.Reason)
+			}
+		}
+	}
+}
+
 type pkginfo struct {
 	pkgname   string
 	pkgver    string
@@ -435,6 +670,10 @@ func (s *scaImpl) Version() string {
 	return fmt.Sprintf("%s-r%d", s.pb.Origin.Version, s.pb.Origin.Epoch)
 }
 
+func (s *scaImpl) Arch() string {
+	return s.pb.Arch
+}
+
 func (s *scaImpl) FilesystemForRelative(pkgName string) (sca.SCAFS, error) {
 	exp, ok := s.exps[pkgName]
 	if !ok {
@@ -482,6 +721,59 @@ func (s *scaImpl) PkgResolver() *apk.PkgResolver {
 	return s.pb.Build.PkgResolver
 }
 
+// dirImpl is an sca.SCAHandle for an unpacked package tree, which has no
+// configuration, relatives or resolvable repositories.
+type dirImpl struct {
+	name    string
+	version string
+	arch    string
+	fsys    sca.SCAFS
+}
+
+func (d *dirImpl) PackageName() string {
+	return d.name
+}
+
+func (d *dirImpl) RelativeNames() []string {
+	return []string{d.name}
+}
+
+func (d *dirImpl) Version() string {
+	return d.version
+}
+
+func (d *dirImpl) Arch() string {
+	return d.arch
+}
+
+func (d *dirImpl) FilesystemForRelative(pkgName string) (sca.SCAFS, error) {
+	if pkgName != d.name {
+		return nil, fmt.Errorf("no package %q", pkgName)
+	}
+
+	return d.fsys, nil
+}
+
+func (d *dirImpl) Filesystem() (sca.SCAFS, error) {
+	return d.fsys, nil
+}
+
+func (d *dirImpl) Options() config.PackageOption {
+	return config.PackageOption{}
+}
+
+func (d *dirImpl) BaseDependencies() config.Dependencies {
+	return config.Dependencies{}
+}
+
+func (d *dirImpl) InstalledPackages() map[string]string {
+	return map[string]string{d.name: d.version}
+}
+
+func (d *dirImpl) PkgResolver() *apk.PkgResolver {
+	return nil
+}
+
 func isComment(b string) bool {
 	return strings.HasPrefix(b, "#")
 }
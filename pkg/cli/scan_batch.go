@@ -0,0 +1,248 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+	"go.opentelemetry.io/otel"
+	"golang.org/x/sync/errgroup"
+)
+
+// packageChanges are the dependencies and provides of a package which the
+// regenerated .PKGINFO adds or removes compared to its APK.
+type packageChanges struct {
+	Config          string   `json:"config"`
+	Arch            string   `json:"arch"`
+	Package         string   `json:"package"`
+	AddedDepends    []string `json:"added_depends,omitempty"`
+	RemovedDepends  []string `json:"removed_depends,omitempty"`
+	AddedProvides   []string `json:"added_provides,omitempty"`
+	RemovedProvides []string `json:"removed_provides,omitempty"`
+}
+
+func (c packageChanges) changed() bool {
+	return len(c.AddedDepends)+len(c.RemovedDepends)+len(c.AddedProvides)+len(c.RemovedProvides) > 0
+}
+
+// scanFailure is a configuration that could not be scanned.
+type scanFailure struct {
+	Config string `json:"config"`
+	Error  string `json:"error"`
+}
+
+// scanReport is the aggregate report of scanning many configurations.
+type scanReport struct {
+	Scanned  int              `json:"scanned"`
+	Changed  []packageChanges `json:"changed"`
+	Failures []scanFailure    `json:"failures,omitempty"`
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// scanConfigFiles returns the configurations named by args, where a directory
+// names all of the configurations in it.
+func scanConfigFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		if !isDir(arg) {
+			files = append(files, arg)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(arg, "*.yaml"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+
+	return files, nil
+}
+
+// pkgInfoValues returns the values of key in a .PKGINFO, sorted.
+func pkgInfoValues(b []byte, key string) []string {
+	var values []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		before, after, ok := strings.Cut(scanner.Text(), "=")
+		if !ok || strings.TrimSpace(before) != key {
+			continue
+		}
+		values = append(values, strings.TrimSpace(after))
+	}
+
+	slices.Sort(values)
+	return slices.Compact(values)
+}
+
+// difference returns the values of a which are not in b.
+func difference(a, b []string) []string {
+	var out []string
+	for _, v := range a {
+		if !slices.Contains(b, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (res scanResult) changes(file string) packageChanges {
+	oldDepends, newDepends := pkgInfoValues(res.old, "depend"), pkgInfoValues(res.generated, "depend")
+	oldProvides, newProvides := pkgInfoValues(res.old, "provides"), pkgInfoValues(res.generated, "provides")
+
+	return packageChanges{
+		Config:          file,
+		Arch:            res.arch,
+		Package:         res.pkgName,
+		AddedDepends:    difference(newDepends, oldDepends),
+		RemovedDepends:  difference(oldDepends, newDepends),
+		AddedProvides:   difference(newProvides, oldProvides),
+		RemovedProvides: difference(oldProvides, newProvides),
+	}
+}
+
+// unsupportedBatchFlag returns the first flag that is set which only applies
+// to scanning a single config, if any.
+func (sc *scanConfig) unsupportedBatchFlag() string {
+	switch {
+	case sc.diff:
+		return "--diff"
+	case sc.comments:
+		return "--comments"
+	case sc.explain:
+		return "--explain"
+	case sc.pkg != "":
+		return "--package"
+	}
+	return ""
+}
+
+// scanBatchCmd regenerates the .PKGINFO of the packages of many
+// configurations in parallel, and reports how their dependencies and
+// provides would change.
+func scanBatchCmd(ctx context.Context, args []string, sc *scanConfig) error {
+	ctx, span := otel.Tracer("melange").Start(ctx, "scan")
+	defer span.End()
+
+	log := clog.FromContext(ctx)
+
+	if sc.format != "text" && sc.format != "json" {
+		return fmt.Errorf("unknown report format %q", sc.format)
+	}
+	if flag := sc.unsupportedBatchFlag(); flag != "" {
+		return fmt.Errorf("%s is not supported when scanning more than one config", flag)
+	}
+
+	files, err := scanConfigFiles(args)
+	if err != nil {
+		return err
+	}
+
+	if err := sc.loadResolvers(ctx); err != nil {
+		return err
+	}
+
+	results := make([][]scanResult, len(files))
+	errs := make([]error, len(files))
+
+	var g errgroup.Group
+	g.SetLimit(max(sc.jobs, 1))
+	for i, file := range files {
+		g.Go(func() error {
+			log.Infof("scanning %s", file)
+			results[i], errs[i] = scanFile(ctx, file, sc)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	report := scanReport{Changed: []packageChanges{}}
+	for i, file := range files {
+		if errs[i] != nil {
+			report.Failures = append(report.Failures, scanFailure{Config: file, Error: errs[i].Error()})
+			continue
+		}
+
+		for _, res := range results[i] {
+			report.Scanned++
+			if c := res.changes(file); c.changed() {
+				report.Changed = append(report.Changed, c)
+			}
+		}
+	}
+
+	if err := writeScanReport(os.Stdout, sc.format, report); err != nil {
+		return err
+	}
+
+	if len(report.Failures) > 0 {
+		return fmt.Errorf("failed to scan %d of %d configs", len(report.Failures), len(files))
+	}
+
+	return nil
+}
+
+// writeScanReport writes the report as json, or as text for any other
+// format, which scanBatchCmd has already checked.
+func writeScanReport(w io.Writer, format string, report scanReport) error {
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	printScanReport(w, report)
+	return nil
+}
+
+func printScanReport(w io.Writer, report scanReport) {
+	for _, c := range report.Changed {
+		fmt.Fprintf(w, "%s (%s, %s)\n", c.Package, c.Arch, c.Config)
+		for _, dep := range c.AddedDepends {
+			fmt.Fprintf(w, "  + depend = %s\n", dep)
+		}
+		for _, dep := range c.RemovedDepends {
+			fmt.Fprintf(w, "  - depend = %s\n", dep)
+		}
+		for _, prov := range c.AddedProvides {
+			fmt.Fprintf(w, "  + provides = %s\n", prov)
+		}
+		for _, prov := range c.RemovedProvides {
+			fmt.Fprintf(w, "  - provides = %s\n", prov)
+		}
+	}
+
+	for _, f := range report.Failures {
+		fmt.Fprintf(w, "error: %s: %s\n", f.Config, f.Error)
+	}
+
+	fmt.Fprintf(w, "scanned %d packages: %d changed, %d configs failed\n", report.Scanned, len(report.Changed), len(report.Failures))
+}
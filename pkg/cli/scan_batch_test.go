@@ -0,0 +1,239 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/chainguard-dev/clog/slogtest"
+	"github.com/stretchr/testify/require"
+)
+
+const testPkgInfo = `# Generated by melange
+pkgname = foo
+pkgver = 1.0.0-r0
+depend = so:libc.so.6
+depend = cmd:sh
+depend = so:libc.so.6
+provides = cmd:foo=1.0.0-r0
+provides=so:libfoo.so.1=1
+`
+
+func TestPkgInfoValues(t *testing.T) {
+	for _, tt := range []struct {
+		key  string
+		want []string
+	}{{
+		key:  "depend",
+		want: []string{"cmd:sh", "so:libc.so.6"},
+	}, {
+		key:  "provides",
+		want: []string{"cmd:foo=1.0.0-r0", "so:libfoo.so.1=1"},
+	}, {
+		key:  "pkgname",
+		want: []string{"foo"},
+	}, {
+		key:  "replaces",
+		want: nil,
+	}, {
+		// Comments aren't values.
+		key:  "# Generated by melange",
+		want: nil,
+	}} {
+		t.Run(tt.key, func(t *testing.T) {
+			require.Equal(t, tt.want, pkgInfoValues([]byte(testPkgInfo), tt.key))
+		})
+	}
+}
+
+func TestScanResultChanges(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		generated string
+		want      packageChanges
+		changed   bool
+	}{{
+		name:      "unchanged",
+		generated: testPkgInfo,
+		want:      packageChanges{Config: "foo.yaml", Arch: "x86_64", Package: "foo"},
+	}, {
+		name: "reordered",
+		generated: `pkgname = foo
+provides = so:libfoo.so.1=1
+provides = cmd:foo=1.0.0-r0
+depend = so:libc.so.6
+depend = cmd:sh
+`,
+		want: packageChanges{Config: "foo.yaml", Arch: "x86_64", Package: "foo"},
+	}, {
+		name: "changed",
+		generated: `pkgname = foo
+depend = so:libc.so.6
+depend = so:libz.so.1
+provides = cmd:foo=1.0.0-r0
+provides = cmd:foo-config=1.0.0-r0
+`,
+		want: packageChanges{
+			Config:          "foo.yaml",
+			Arch:            "x86_64",
+			Package:         "foo",
+			AddedDepends:    []string{"so:libz.so.1"},
+			RemovedDepends:  []string{"cmd:sh"},
+			AddedProvides:   []string{"cmd:foo-config=1.0.0-r0"},
+			RemovedProvides: []string{"so:libfoo.so.1=1"},
+		},
+		changed: true,
+	}, {
+		name:      "everything removed",
+		generated: "pkgname = foo\n",
+		want: packageChanges{
+			Config:          "foo.yaml",
+			Arch:            "x86_64",
+			Package:         "foo",
+			RemovedDepends:  []string{"cmd:sh", "so:libc.so.6"},
+			RemovedProvides: []string{"cmd:foo=1.0.0-r0", "so:libfoo.so.1=1"},
+		},
+		changed: true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			res := scanResult{
+				arch:      "x86_64",
+				pkgName:   "foo",
+				old:       []byte(testPkgInfo),
+				generated: []byte(tt.generated),
+			}
+
+			got := res.changes("foo.yaml")
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.changed, got.changed())
+		})
+	}
+}
+
+func TestWriteScanReport(t *testing.T) {
+	report := scanReport{
+		Scanned: 3,
+		Changed: []packageChanges{{
+			Config:          "foo.yaml",
+			Arch:            "aarch64",
+			Package:         "foo-dev",
+			AddedDepends:    []string{"pc:zlib"},
+			RemovedProvides: []string{"pc:foo=1.0.0-r0"},
+		}},
+		Failures: []scanFailure{{
+			Config: "bar.yaml",
+			Error:  "open packages/x86_64/bar-1.0.0-r0.apk: no such file or directory",
+		}},
+	}
+
+	for _, tt := range []struct {
+		format string
+		report scanReport
+		want   string
+	}{{
+		format: "text",
+		report: report,
+		want: `foo-dev (aarch64, foo.yaml)
+  + depend = pc:zlib
+  - provides = pc:foo=1.0.0-r0
+error: bar.yaml: open packages/x86_64/bar-1.0.0-r0.apk: no such file or directory
+scanned 3 packages: 1 changed, 1 configs failed
+`,
+	}, {
+		format: "text",
+		report: scanReport{Scanned: 2, Changed: []packageChanges{}},
+		want:   "scanned 2 packages: 0 changed, 0 configs failed\n",
+	}, {
+		format: "json",
+		report: report,
+		want: `{
+  "scanned": 3,
+  "changed": [
+    {
+      "config": "foo.yaml",
+      "arch": "aarch64",
+      "package": "foo-dev",
+      "added_depends": [
+        "pc:zlib"
+      ],
+      "removed_provides": [
+        "pc:foo=1.0.0-r0"
+      ]
+    }
+  ],
+  "failures": [
+    {
+      "config": "bar.yaml",
+      "error": "open packages/x86_64/bar-1.0.0-r0.apk: no such file or directory"
+    }
+  ]
+}
+`,
+	}, {
+		format: "json",
+		report: scanReport{Scanned: 2, Changed: []packageChanges{}},
+		want: `{
+  "scanned": 2,
+  "changed": []
+}
+`,
+	}} {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeScanReport(&buf, tt.format, tt.report))
+			require.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestUnsupportedBatchFlag(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		sc   scanConfig
+		want string
+	}{{
+		name: "none",
+		sc:   scanConfig{jobs: 4, format: "json", archs: []string{"aarch64"}},
+	}, {
+		name: "diff",
+		sc:   scanConfig{diff: true},
+		want: "--diff",
+	}, {
+		name: "comments",
+		sc:   scanConfig{comments: true},
+		want: "--comments",
+	}, {
+		name: "explain",
+		sc:   scanConfig{explain: true},
+		want: "--explain",
+	}, {
+		name: "package",
+		sc:   scanConfig{pkg: "foo-dev"},
+		want: "--package",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.sc.unsupportedBatchFlag())
+		})
+	}
+}
+
+func TestScanBatchFormat(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	// The format is checked before anything is scanned.
+	sc := scanConfig{format: "yaml"}
+	require.ErrorContains(t, scanBatchCmd(ctx, []string{"does-not-exist"}, &sc), `unknown report format "yaml"`)
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chainguard-dev/clog/slogtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanDir(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	dir := filepath.Join(t.TempDir(), "foo")
+	for path, file := range map[string]struct {
+		content string
+		mode    os.FileMode
+	}{
+		"usr/bin/foo": {
+			content: "#!/bin/bash\necho foo\n",
+			mode:    0o755,
+		},
+		"usr/lib/pkgconfig/foo.pc": {
+			content: `prefix=/usr
+libdir=${prefix}/lib
+
+Name: foo
+Description: The foo library
+Version: 1.2.3
+Requires: zlib
+Libs: -L${libdir} -lfoo
+`,
+			mode: 0o644,
+		},
+	} {
+		p := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(file.content), file.mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		name string
+		sc   scanConfig
+		want string
+	}{{
+		name: "default name",
+		sc:   scanConfig{dir: dir, version: "1.2.3-r0"},
+		want: `# foo
+depend = cmd:bash
+depend = pc:zlib
+provides = cmd:foo=1.2.3-r0
+provides = pc:foo=1.2.3-r0
+`,
+	}, {
+		name: "named",
+		sc:   scanConfig{dir: dir, name: "foo-dev", version: "1.2.3-r0"},
+		want: `# foo-dev
+depend = cmd:bash
+depend = pc:zlib
+provides = cmd:foo=1.2.3-r0
+provides = pc:foo=1.2.3-r0
+`,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := scanDirCmd(ctx, &buf, &tt.sc); err != nil {
+				t.Fatal(err)
+			}
+			require.Equal(t, tt.want, buf.String())
+		})
+	}
+
+	t.Run("not a directory", func(t *testing.T) {
+		sc := scanConfig{dir: filepath.Join(dir, "usr/bin/foo")}
+		require.Error(t, scanDirCmd(ctx, &bytes.Buffer{}, &sc))
+	})
+}
@@ -93,11 +93,18 @@ type PackageOption struct {
 	NoCommands bool `json:"no-commands,omitempty" yaml:"no-commands,omitempty"`
 	// Optional: Don't generate versioned depends for shared libraries
 	NoVersionedShlibDeps bool `json:"no-versioned-shlib-deps,omitempty" yaml:"no-versioned-shlib-deps,omitempty"`
+	// Optional: Shared objects this package loads with dlopen, which are added
+	// as runtime dependencies
+	Dlopen []string `json:"dlopen,omitempty" yaml:"dlopen,omitempty"`
 }
 
 type Checks struct {
 	// Optional: disable these linters that are not enabled by default.
 	Disabled []string `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+	// Optional: the hardening properties the hardening linter requires of the
+	// binaries in the package: pie, relro, bindnow, nx, stack-protector and
+	// fortify. Defaults to pie, relro, bindnow and nx.
+	Hardening []string `json:"hardening,omitempty" yaml:"hardening,omitempty"`
 }
 
 type Package struct {
@@ -533,6 +540,9 @@ type Pipeline struct {
 	WorkDir string `json:"working-directory,omitempty" yaml:"working-directory,omitempty"`
 	// Optional: environment variables to override apko
 	Environment map[string]string `json:"environment,omitempty" yaml:"environment,omitempty"`
+
+	// Where the pipeline, and its runs, are defined in the YAML.
+	pos, runsPos Position
 }
 
 // SHA256 generates a digest based on the text provided
@@ -795,6 +805,9 @@ type Capabilities struct {
 
 // Configuration is the root melange configuration.
 type Configuration struct {
+	// Optional: Fragment files to merge into this configuration, relative to
+	// it. They are merged when the configuration is parsed, leaving this empty.
+	Include []string `json:"include,omitempty" yaml:"include,omitempty"`
 	// Package metadata
 	Package Package `json:"package" yaml:"package"`
 	// The specification for the packages build environment
@@ -826,6 +839,13 @@ type Configuration struct {
 
 	// Parsed AST for this configuration
 	root *yaml.Node
+	// The configuration with its includes merged, the files they were read
+	// from, and where each of the subpackages is defined.
+	tree          *yaml.Node
+	file          string
+	files         map[*yaml.Node]string
+	includes      []Include
+	subpackagePos []Position
 }
 
 // AllPackageNames returns a sequence of all package names in the configuration,
@@ -1277,6 +1297,8 @@ func replacePipeline(r *strings.Replacer, in Pipeline) Pipeline {
 		Assertions:  in.Assertions,
 		WorkDir:     r.Replace(in.WorkDir),
 		Environment: replaceMap(r, in.Environment),
+		pos:         in.pos,
+		runsPos:     in.runsPos,
 	}
 }
 
@@ -1392,7 +1414,7 @@ func replaceSubpackages(r *strings.Replacer, datas map[string]DataItems, cfg Con
 
 		items, ok := datas[sp.Range]
 		if !ok {
-			return nil, fmt.Errorf("subpackages[%d] (%q) specified undefined range: %q", i, sp.Name, sp.Range)
+			return nil, WithPosition(cfg.subpackageKeyPosition(i, "range"), fmt.Errorf("subpackages[%d] (%q) specified undefined range: %q", i, sp.Name, sp.Range))
 		}
 
 		// Ensure iterating over items is deterministic by sorting keys alphabetically
@@ -1457,19 +1479,22 @@ func ParseConfiguration(ctx context.Context, configurationFilePath string, opts
 	configurationDirPath := filepath.Dir(configurationFilePath)
 	options.include(opts...)
 
+	// The configuration is opened by its name in the filesystem, but
+	// positions in it keep the path it was given by.
+	name := configurationFilePath
 	if options.filesystem == nil {
 		// TODO: this is an abstraction leak, and we can remove this `if statement` once
 		//  ParseConfiguration relies solely on an abstract fs.FS.
 
 		options.filesystem = os.DirFS(configurationDirPath)
-		configurationFilePath = filepath.Base(configurationFilePath)
+		name = filepath.Base(configurationFilePath)
 	}
 
 	if configurationFilePath == "" {
 		return nil, errors.New("no configuration file path provided")
 	}
 
-	f, err := options.filesystem.Open(configurationFilePath)
+	f, err := options.filesystem.Open(name)
 	if err != nil {
 		return nil, err
 	}
@@ -1477,17 +1502,24 @@ func ParseConfiguration(ctx context.Context, configurationFilePath string, opts
 
 	root := yaml.Node{}
 
-	cfg := Configuration{root: &root}
+	cfg := Configuration{root: &root, file: configurationFilePath}
 
 	// Unmarshal into a node first
 	decoderNode := yaml.NewDecoder(f)
 	err = decoderNode.Decode(&root)
 	if err != nil {
-		return nil, fmt.Errorf("unable to decode configuration file %q: %w", configurationFilePath, err)
+		return nil, WithPosition(syntaxErrorPosition(configurationFilePath, err), fmt.Errorf("unable to decode configuration file %q: %w", configurationFilePath, err))
+	}
+
+	// Merge the included fragments into a copy of the document, keeping the
+	// root as written.
+	cfg.tree, err = cfg.resolveIncludes(options.filesystem, name, &root)
+	if err != nil {
+		return nil, fmt.Errorf("resolving includes of configuration file %q: %w", configurationFilePath, err)
 	}
 
 	// XXX(Elizafox) - Node.Decode doesn't allow setting of KnownFields, so we do this cheesy hack below
-	data, err := yaml.Marshal(&root)
+	data, err := yaml.Marshal(cfg.tree)
 	if err != nil {
 		return nil, fmt.Errorf("unable to decode configuration file %q: %w", configurationFilePath, err)
 	}
@@ -1497,9 +1529,11 @@ func ParseConfiguration(ctx context.Context, configurationFilePath string, opts
 	decoder := yaml.NewDecoder(reader)
 	decoder.KnownFields(true)
 	if err := decoder.Decode(&cfg); err != nil {
-		return nil, fmt.Errorf("unable to decode configuration file %q: %w", configurationFilePath, err)
+		return nil, fmt.Errorf("unable to decode configuration file %q: %w", configurationFilePath, decodeError(cfg.nodePosition, cfg.tree, data, err))
 	}
 
+	cfg.annotatePositions()
+
 	// If a variables file was defined, merge it into the variables block.
 	if varsFile := options.varsFilePath; varsFile != "" {
 		f, err := os.Open(varsFile)
@@ -1534,6 +1568,8 @@ func ParseConfiguration(ctx context.Context, configurationFilePath string, opts
 		datas[d.Name] = d.Items
 	}
 
+	cfg.annotateSubpackages(datas, cfg.Subpackages)
+
 	cfg.Subpackages, err = replaceSubpackages(replacer, datas, cfg, cfg.Subpackages)
 	if err != nil {
 		return nil, fmt.Errorf("unable to decode configuration file %q: %w", configurationFilePath, err)
@@ -1692,35 +1728,37 @@ var packageNameRegex = regexp.MustCompile(`^[a-zA-Z\d][a-zA-Z\d+_.-]*$`)
 
 func (cfg Configuration) validate(ctx context.Context) error {
 	if !packageNameRegex.MatchString(cfg.Package.Name) {
-		return ErrInvalidConfiguration{Problem: fmt.Errorf("package name must match regex %q", packageNameRegex)}
+		return ErrInvalidConfiguration{Problem: WithPosition(cfg.position("package", "name"), fmt.Errorf("package name must match regex %q", packageNameRegex))}
 	}
 
 	if cfg.Package.Version == "" {
-		return ErrInvalidConfiguration{Problem: errors.New("package version must not be empty")}
+		return ErrInvalidConfiguration{Problem: WithPosition(cfg.position("package", "version"), errors.New("package version must not be empty"))}
 	}
 
 	// TODO: try to validate value of .package.version
 
 	if err := validateDependenciesPriorities(cfg.Package.Dependencies); err != nil {
-		return ErrInvalidConfiguration{Problem: errors.New("priority must convert to integer")}
+		return ErrInvalidConfiguration{Problem: WithPosition(cfg.position("package", "dependencies"), errors.New("priority must convert to integer"))}
 	}
 	if err := validatePipelines(ctx, cfg.Pipeline); err != nil {
 		return ErrInvalidConfiguration{Problem: err}
 	}
 	if err := validateCapabilities(cfg.Package.SetCap); err != nil {
-		return ErrInvalidConfiguration{Problem: err}
+		return ErrInvalidConfiguration{Problem: WithPosition(cfg.position("package", "setcap"), err)}
 	}
 
 	saw := map[string]int{cfg.Package.Name: -1}
 	for i, sp := range cfg.Subpackages {
+		pos := cfg.subpackagePosition(i)
+
 		if extant, ok := saw[sp.Name]; ok {
 			if extant == -1 {
 				return ErrInvalidConfiguration{
-					Problem: fmt.Errorf("subpackage[%d] has same name as main package: %q", i, sp.Name),
+					Problem: WithPosition(pos, fmt.Errorf("subpackage[%d] has same name as main package: %q", i, sp.Name)),
 				}
 			} else {
 				return ErrInvalidConfiguration{
-					Problem: fmt.Errorf("saw duplicate subpackage name %q (subpackages index: %d and %d)", sp.Name, extant, i),
+					Problem: WithPosition(pos, fmt.Errorf("saw duplicate subpackage name %q (subpackages index: %d and %d)", sp.Name, extant, i)),
 				}
 			}
 		}
@@ -1728,21 +1766,21 @@ func (cfg Configuration) validate(ctx context.Context) error {
 		saw[sp.Name] = i
 
 		if !packageNameRegex.MatchString(sp.Name) {
-			return ErrInvalidConfiguration{Problem: fmt.Errorf("subpackage name %q (subpackages index: %d) must match regex %q", sp.Name, i, packageNameRegex)}
+			return ErrInvalidConfiguration{Problem: WithPosition(pos, fmt.Errorf("subpackage name %q (subpackages index: %d) must match regex %q", sp.Name, i, packageNameRegex))}
 		}
 		if err := validateDependenciesPriorities(sp.Dependencies); err != nil {
-			return ErrInvalidConfiguration{Problem: errors.New("priority must convert to integer")}
+			return ErrInvalidConfiguration{Problem: WithPosition(pos, errors.New("priority must convert to integer"))}
 		}
 		if err := validatePipelines(ctx, sp.Pipeline); err != nil {
 			return ErrInvalidConfiguration{Problem: err}
 		}
 		if err := validateCapabilities(sp.SetCap); err != nil {
-			return ErrInvalidConfiguration{Problem: err}
+			return ErrInvalidConfiguration{Problem: WithPosition(pos, err)}
 		}
 	}
 
 	if err := validateCPE(cfg.Package.CPE); err != nil {
-		return ErrInvalidConfiguration{Problem: fmt.Errorf("CPE validation: %w", err)}
+		return ErrInvalidConfiguration{Problem: WithPosition(cfg.position("package", "cpe"), fmt.Errorf("CPE validation: %w", err))}
 	}
 
 	return nil
@@ -1764,19 +1802,19 @@ func validatePipelines(ctx context.Context, ps []Pipeline) error {
 	log := clog.FromContext(ctx)
 	for i, p := range ps {
 		if p.With != nil && p.Uses == "" {
-			return fmt.Errorf("pipeline contains with but no uses")
+			return WithPosition(p.pos, fmt.Errorf("pipeline contains with but no uses"))
 		}
 
 		if p.Uses != "" && p.Runs != "" {
-			return fmt.Errorf("pipeline cannot contain both uses %q and runs", p.Uses)
+			return WithPosition(p.pos, fmt.Errorf("pipeline cannot contain both uses %q and runs", p.Uses))
 		}
 
 		if p.Uses != "" && len(p.Pipeline) > 0 {
-			log.Warnf("pipeline %s contains both uses and a pipeline", pipelineName(p, i))
+			log.Warnf("%v", FormatError(WithPosition(p.pos, fmt.Errorf("pipeline %s contains both uses and a pipeline", pipelineName(p, i)))))
 		}
 
 		if len(p.With) > 0 && p.Runs != "" {
-			return fmt.Errorf("pipeline cannot contain both with and runs")
+			return WithPosition(p.pos, fmt.Errorf("pipeline cannot contain both with and runs"))
 		}
 
 		if err := validatePipelines(ctx, p.Pipeline); err != nil {
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/chainguard-dev/clog/slogtest"
 	purl "github.com/package-url/packageurl-go"
@@ -1387,3 +1388,278 @@ func TestPackageURL(t *testing.T) {
 		})
 	}
 }
+
+func TestPipelinePositions(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	fp := filepath.Join(t.TempDir(), "positions.yaml")
+	if err := os.WriteFile(fp, []byte(`package:
+  name: positions
+  version: 0.0.1
+  epoch: 0
+
+data:
+  - name: items
+    items:
+      a: A
+      b: B
+
+pipeline:
+  - runs: echo one
+  - name: nested
+    pipeline:
+      - runs: |
+          echo two
+
+subpackages:
+  - range: items
+    name: ${{range.key}}
+    pipeline:
+      - runs: "echo ${{range.value}}"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := ParseConfiguration(ctx, fp)
+	if err != nil {
+		t.Fatalf("failed to parse configuration: %s", err)
+	}
+
+	require.Equal(t, Position{File: fp, Line: 13, Column: 5}, cfg.Pipeline[0].Position())
+	require.Equal(t, Position{File: fp, Line: 13, Column: 11}, cfg.Pipeline[0].RunsPosition())
+	require.Equal(t, Position{File: fp, Line: 16, Column: 9}, cfg.Pipeline[1].Pipeline[0].Position())
+	require.Equal(t, Position{File: fp, Line: 17}, cfg.Pipeline[1].Pipeline[0].RunsPosition())
+	require.Equal(t, fp+":17", cfg.Pipeline[1].Pipeline[0].RunsPosition().String())
+
+	// Every expanded subpackage points back at the ranged definition.
+	require.Len(t, cfg.Subpackages, 2)
+	for _, sp := range cfg.Subpackages {
+		require.Equal(t, Position{File: fp, Line: 23, Column: 16}, sp.Pipeline[0].RunsPosition())
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	for _, tt := range []struct {
+		name    string
+		config  string
+		wantPos string
+		wantErr string
+	}{{
+		name: "unknown field",
+		config: `package:
+  name: bad
+  version: 0.0.1
+
+  # The field below is misspelled.
+  epock: 1
+`,
+		wantPos: ":6:3",
+		wantErr: "line 6: field epock not found",
+	}, {
+		name: "syntax error",
+		config: `package:
+  name: bad
+  version: 0.0.1
+ epoch: 1
+`,
+		// This is where the YAML parser notices the problem.
+		wantPos: ":3",
+	}, {
+		name: "invalid pipeline",
+		config: `package:
+  name: bad
+  version: 0.0.1
+pipeline:
+  - runs: echo ok
+  - pipeline:
+      - runs: echo nested
+        with:
+          foo: bar
+`,
+		wantPos: ":7:9",
+		wantErr: "pipeline contains with but no uses",
+	}, {
+		name: "duplicate subpackage",
+		config: `package:
+  name: bad
+  version: 0.0.1
+subpackages:
+  - name: bad-doc
+  - name: bad-doc
+`,
+		wantPos: ":6:11",
+		wantErr: "saw duplicate subpackage name",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := filepath.Join(t.TempDir(), "bad.yaml")
+			if err := os.WriteFile(fp, []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := ParseConfiguration(ctx, fp)
+			require.Error(t, err)
+			require.ErrorContains(t, err, tt.wantErr)
+
+			pos, ok := ErrorPosition(err)
+			require.True(t, ok, "error has no position: %v", err)
+			require.Equal(t, fp+tt.wantPos, pos.String())
+			require.True(t, strings.HasPrefix(FormatError(err).Error(), fp+tt.wantPos+": "))
+		})
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	fsys := fstest.MapFS{
+		"pkgs/foo.yaml": {Data: []byte(`include:
+  - ../shared/env.yaml
+  - ../shared/test.yaml
+package:
+  name: foo
+  version: 1.2.3
+  epoch: 0
+environment:
+  contents:
+    packages:
+      - go
+      - busybox
+vars:
+  flavor: foo
+pipeline:
+  - runs: echo ${{vars.flavor}} ${{vars.base}}
+`)},
+		"shared/env.yaml": {Data: []byte(`include:
+  - common.yaml
+environment:
+  contents:
+    packages:
+      - busybox
+      - build-base
+  environment:
+    CGO_ENABLED: "0"
+vars:
+  flavor: shared
+  base: env
+`)},
+		"shared/common.yaml": {Data: []byte(`environment:
+  environment:
+    CGO_ENABLED: "1"
+    LANG: C
+`)},
+		"shared/test.yaml": {Data: []byte(`include:
+  - common.yaml
+test:
+  pipeline:
+    - runs: |
+        foo --version
+`)},
+	}
+
+	cfg, err := ParseConfiguration(ctx, "pkgs/foo.yaml", WithFS(fsys))
+	if err != nil {
+		t.Fatalf("failed to parse configuration: %s", err)
+	}
+
+	// Lists are concatenated, leaving out duplicate scalars.
+	require.Equal(t, []string{"busybox", "build-base", "go"}, cfg.Environment.Contents.Packages)
+	// Mappings are merged, later files winning.
+	require.Equal(t, "0", cfg.Environment.Environment["CGO_ENABLED"])
+	require.Equal(t, "C", cfg.Environment.Environment["LANG"])
+	require.Equal(t, "echo foo env", cfg.Pipeline[0].Runs)
+	require.Len(t, cfg.Test.Pipeline, 1)
+	require.Empty(t, cfg.Include)
+
+	// Positions point at the fragment a pipeline comes from.
+	require.Equal(t, Position{File: filepath.Join("shared", "test.yaml"), Line: 5, Column: 7}, cfg.Test.Pipeline[0].Position())
+
+	// The root is kept as written, so it can be modified and written back.
+	require.NotNil(t, mappingValue(documentContent(cfg.Root()), "include"))
+
+	var paths []string
+	for _, inc := range cfg.Includes() {
+		paths = append(paths, inc.Path)
+		require.Len(t, inc.SHA256, 64)
+	}
+	require.Equal(t, []string{"../shared/common.yaml", "../shared/env.yaml", "../shared/test.yaml"}, paths)
+}
+
+func TestIncludeErrors(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	for _, tt := range []struct {
+		name    string
+		files   fstest.MapFS
+		wantErr string
+		wantPos string
+	}{{
+		name: "cycle",
+		files: fstest.MapFS{
+			"a.yaml": {Data: []byte("include:\n  - b.yaml\n")},
+			"b.yaml": {Data: []byte("include:\n  - a.yaml\n")},
+		},
+		wantErr: "include cycle",
+		wantPos: "b.yaml:2:5",
+	}, {
+		name: "outside",
+		files: fstest.MapFS{
+			"a.yaml": {Data: []byte("include:\n  - ../b.yaml\n")},
+		},
+		wantErr: "outside of the configuration directory",
+		wantPos: "a.yaml:2:5",
+	}, {
+		name: "missing",
+		files: fstest.MapFS{
+			"a.yaml": {Data: []byte("package:\n  name: a\ninclude:\n  - b.yaml\n")},
+		},
+		wantErr: "reading included file",
+		wantPos: "a.yaml:4:5",
+	}, {
+		name: "unknown field in fragment",
+		files: fstest.MapFS{
+			"a.yaml": {Data: []byte("include:\n  - b.yaml\npackage:\n  name: a\n  version: 1.0.0\n")},
+			"b.yaml": {Data: []byte("package:\n  epock: 1\n")},
+		},
+		wantErr: "field epock not found",
+		wantPos: "b.yaml:2:3",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfiguration(ctx, "a.yaml", WithFS(tt.files))
+			require.ErrorContains(t, err, tt.wantErr)
+
+			pos, ok := ErrorPosition(err)
+			require.True(t, ok, "error has no position: %v", err)
+			require.Equal(t, tt.wantPos, pos.String())
+		})
+	}
+}
+
+func TestPackagePosition(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	fsys := fstest.MapFS{
+		"foo.yaml": {Data: []byte(`package:
+  name: foo
+  version: 1.0.0
+  epoch: 0
+subpackages:
+  - name: foo-doc
+  - range: flavors
+    name: foo-${{range.key}}
+data:
+  - name: flavors
+    items:
+      a: A
+`)},
+	}
+
+	cfg, err := ParseConfiguration(ctx, "foo.yaml", WithFS(fsys))
+	require.NoError(t, err)
+
+	require.Equal(t, Position{File: "foo.yaml", Line: 2, Column: 9}, cfg.PackagePosition("foo"))
+	require.Equal(t, 6, cfg.PackagePosition("foo-doc").Line)
+	require.Equal(t, 8, cfg.PackagePosition("foo-a").Line)
+	require.True(t, cfg.PackagePosition("bar").IsZero())
+}
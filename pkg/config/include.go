@@ -0,0 +1,280 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+const includeKey = "include"
+
+// Include is a fragment that was merged into a configuration.
+type Include struct {
+	// The path of the fragment, relative to the configuration file.
+	Path string `json:"path"`
+	// The hex encoded SHA-256 digest of the fragment.
+	SHA256 string `json:"sha256"`
+}
+
+// Includes returns the fragments that were merged into the configuration, in
+// the order they were merged.
+func (cfg Configuration) Includes() []Include {
+	return cfg.includes
+}
+
+// includeResolver merges the fragments included by a configuration.
+type includeResolver struct {
+	fsys fs.FS
+	// The path of the configuration in fsys, and how to display it.
+	configPath, displayPath string
+
+	// The files each node of an included fragment was read from.
+	files map[*yaml.Node]string
+	// The fragments merged so far.
+	includes []Include
+	// The fragments being resolved, to detect cycles.
+	stack []string
+}
+
+// resolveIncludes returns the configuration document with the fragments it
+// includes merged in, without modifying root, which is kept as written.
+//
+// Fragments are merged in order, each on top of the previous ones, and the
+// configuration on top of them all:
+//   - mappings are merged key by key,
+//   - sequences are concatenated, leaving out scalars that are already present,
+//   - anything else is replaced.
+//
+// Fragments may include other fragments, relative to themselves. A fragment is
+// only merged the first time it is included.
+func (cfg *Configuration) resolveIncludes(fsys fs.FS, configPath string, root *yaml.Node) (*yaml.Node, error) {
+	r := &includeResolver{
+		fsys:        fsys,
+		configPath:  configPath,
+		displayPath: cfg.file,
+		files:       map[*yaml.Node]string{},
+		stack:       []string{configPath},
+	}
+
+	tree, err := r.resolve(configPath, root)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.files = r.files
+	cfg.includes = r.includes
+
+	return tree, nil
+}
+
+// resolve merges the fragments included by doc, read from fsPath.
+func (r *includeResolver) resolve(fsPath string, doc *yaml.Node) (*yaml.Node, error) {
+	at := r.positioner(fsPath)
+
+	content := documentContent(doc)
+	if content == nil || content.Kind != yaml.MappingNode {
+		if fsPath == r.configPath {
+			return doc, nil
+		}
+		return nil, WithPosition(at(content), fmt.Errorf("included file %q must be a mapping", r.display(fsPath)))
+	}
+
+	include := mappingValue(content, includeKey)
+	if include == nil {
+		return doc, nil
+	}
+	if include.Kind != yaml.SequenceNode {
+		return nil, WithPosition(at(include), fmt.Errorf("%s must be a list of files", includeKey))
+	}
+
+	var merged *yaml.Node
+	for _, item := range include.Content {
+		if item.Kind != yaml.ScalarNode || item.Value == "" {
+			return nil, WithPosition(at(item), fmt.Errorf("%s must be a list of files", includeKey))
+		}
+
+		fragment, err := r.load(path.Dir(fsPath), item)
+		if err != nil {
+			return nil, WithPosition(at(item), err)
+		}
+		if fragment == nil {
+			continue
+		}
+
+		if merged == nil {
+			merged = fragment
+		} else {
+			merged = r.merge(merged, fragment)
+		}
+	}
+
+	// Drop the include key from our copy of the document, so the result can
+	// be decoded, and isn't included again if it is parsed again.
+	own := *content
+	own.Content = nil
+	for i := 0; i+1 < len(content.Content); i += 2 {
+		if content.Content[i].Value != includeKey {
+			own.Content = append(own.Content, content.Content[i], content.Content[i+1])
+		}
+	}
+	if file, ok := r.files[content]; ok {
+		r.files[&own] = file
+	}
+
+	if merged != nil {
+		merged = r.merge(merged, &own)
+	} else {
+		merged = &own
+	}
+
+	out := *doc
+	if out.Kind == yaml.DocumentNode {
+		out.Content = []*yaml.Node{merged}
+		return &out, nil
+	}
+	return merged, nil
+}
+
+// load reads and resolves the fragment named by item, relative to dir. It
+// returns nil for fragments that have already been merged.
+func (r *includeResolver) load(dir string, item *yaml.Node) (*yaml.Node, error) {
+	if path.IsAbs(item.Value) {
+		return nil, fmt.Errorf("included file %q must be relative to the configuration", item.Value)
+	}
+
+	fsPath := path.Join(dir, item.Value)
+	if !fs.ValidPath(fsPath) {
+		return nil, fmt.Errorf("included file %q is outside of the configuration directory", item.Value)
+	}
+
+	if slices.Contains(r.stack, fsPath) {
+		return nil, fmt.Errorf("include cycle: %q includes itself", r.display(fsPath))
+	}
+
+	rel := r.relative(fsPath)
+	if slices.ContainsFunc(r.includes, func(inc Include) bool { return inc.Path == rel }) {
+		return nil, nil
+	}
+
+	data, err := fs.ReadFile(r.fsys, fsPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading included file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, WithPosition(syntaxErrorPosition(r.display(fsPath), err), fmt.Errorf("unable to decode included file %q: %w", r.display(fsPath), err))
+	}
+
+	r.record(&doc, r.display(fsPath))
+
+	r.stack = append(r.stack, fsPath)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+
+	resolved, err := r.resolve(fsPath, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	r.includes = append(r.includes, Include{Path: rel, SHA256: hex.EncodeToString(sum[:])})
+
+	return documentContent(resolved), nil
+}
+
+// merge returns override merged on top of base, without modifying either.
+func (r *includeResolver) merge(base, override *yaml.Node) *yaml.Node {
+	if base.Kind != override.Kind {
+		return override
+	}
+
+	out := *override
+	if file, ok := r.files[override]; ok {
+		r.files[&out] = file
+	}
+
+	switch override.Kind {
+	case yaml.MappingNode:
+		out.Content = slices.Clone(base.Content)
+		for i := 0; i+1 < len(override.Content); i += 2 {
+			key, value := override.Content[i], override.Content[i+1]
+
+			found := false
+			for j := 0; j+1 < len(out.Content); j += 2 {
+				if out.Content[j].Value == key.Value {
+					out.Content[j+1] = r.merge(out.Content[j+1], value)
+					found = true
+					break
+				}
+			}
+			if !found {
+				out.Content = append(out.Content, key, value)
+			}
+		}
+
+	case yaml.SequenceNode:
+		out.Content = slices.Clone(base.Content)
+		for _, item := range override.Content {
+			if item.Kind == yaml.ScalarNode && slices.ContainsFunc(base.Content, func(n *yaml.Node) bool {
+				return n.Kind == yaml.ScalarNode && n.Value == item.Value
+			}) {
+				continue
+			}
+			out.Content = append(out.Content, item)
+		}
+
+	default:
+		return override
+	}
+
+	return &out
+}
+
+// record remembers that every node of doc was read from file.
+func (r *includeResolver) record(doc *yaml.Node, file string) {
+	r.files[doc] = file
+	for _, n := range doc.Content {
+		r.record(n, file)
+	}
+}
+
+func (r *includeResolver) positioner(fsPath string) positioner {
+	if fsPath == r.configPath {
+		return inFile(r.displayPath)
+	}
+	return inFile(r.display(fsPath))
+}
+
+// relative returns the path of a fragment relative to the configuration.
+func (r *includeResolver) relative(fsPath string) string {
+	rel, err := filepath.Rel(path.Dir(r.configPath), fsPath)
+	if err != nil {
+		return fsPath
+	}
+	return filepath.ToSlash(rel)
+}
+
+// display returns how to show the path of a fragment in errors.
+func (r *includeResolver) display(fsPath string) string {
+	return filepath.Join(filepath.Dir(r.displayPath), filepath.FromSlash(r.relative(fsPath)))
+}
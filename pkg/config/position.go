@@ -0,0 +1,364 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position is the location of a node in a YAML file.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// IsZero reports whether the position is unknown.
+func (p Position) IsZero() bool {
+	return p.Line == 0
+}
+
+// String formats the position as file:line:col, leaving out the parts that
+// are unknown.
+func (p Position) String() string {
+	switch {
+	case p.Line == 0:
+		return p.File
+	case p.Column == 0:
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	default:
+		return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	}
+}
+
+// PositionError is an error about the YAML node at Pos. The position isn't part
+// of the message, use FormatError to include it.
+type PositionError struct {
+	Pos Position
+	Err error
+}
+
+func (e *PositionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+// WithPosition records that err is about the YAML node at pos, unless the
+// position is unknown, or err already has a more precise one.
+func WithPosition(pos Position, err error) error {
+	if err == nil || pos.IsZero() {
+		return err
+	}
+	if _, ok := ErrorPosition(err); ok {
+		return err
+	}
+	return &PositionError{Pos: pos, Err: err}
+}
+
+// ErrorPosition returns the position of the YAML node err is about, if known.
+func ErrorPosition(err error) (Position, bool) {
+	var perr *PositionError
+	if errors.As(err, &perr) {
+		return perr.Pos, true
+	}
+	return Position{}, false
+}
+
+// FormatError prefixes the message of err with its position, if known, like
+// compilers do: file:line:col: message.
+func FormatError(err error) error {
+	if pos, ok := ErrorPosition(err); ok {
+		return fmt.Errorf("%s: %w", pos, err)
+	}
+	return err
+}
+
+func nodePosition(file string, node *yaml.Node) Position {
+	if node == nil {
+		return Position{File: file}
+	}
+	return Position{File: file, Line: node.Line, Column: node.Column}
+}
+
+// positioner returns the position of a YAML node.
+type positioner func(*yaml.Node) Position
+
+// inFile returns a positioner for nodes read from file.
+func inFile(file string) positioner {
+	return func(node *yaml.Node) Position {
+		return nodePosition(file, node)
+	}
+}
+
+// nodePosition returns the position of a node of the configuration, which is
+// in the file of the fragment it was included from, if any.
+func (cfg Configuration) nodePosition(node *yaml.Node) Position {
+	if file, ok := cfg.files[node]; ok {
+		return nodePosition(file, node)
+	}
+	return nodePosition(cfg.file, node)
+}
+
+// documentContent returns the top-level node of a YAML document.
+func documentContent(node *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return node.Content[0]
+	}
+	return node
+}
+
+// mappingValue returns the value of key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sequenceItem returns the i-th item of a sequence node, or nil.
+func sequenceItem(node *yaml.Node, i int) *yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode || i < 0 || i >= len(node.Content) {
+		return nil
+	}
+	return node.Content[i]
+}
+
+// Position returns where the pipeline is defined, or referenced with `uses`.
+func (p Pipeline) Position() Position {
+	return p.pos
+}
+
+// RunsPosition returns the position of the first line of the pipeline's
+// `runs` script. The column is only known for scripts that aren't block
+// scalars.
+func (p Pipeline) RunsPosition() Position {
+	return p.runsPos
+}
+
+// annotate records the positions of the pipeline and its nested pipelines,
+// given the YAML node it was decoded from.
+func (p *Pipeline) annotate(at positioner, node *yaml.Node) {
+	p.pos = at(node)
+	p.annotateDefinition(at, node)
+}
+
+// AnnotateDefinition records the positions of the `runs` script and nested
+// pipelines of p, given the YAML node of its definition. This is used when the
+// definition of a `uses` pipeline is loaded into p, so the position of p itself
+// is left as where the pipeline was used.
+func (p *Pipeline) AnnotateDefinition(file string, node *yaml.Node) {
+	p.annotateDefinition(inFile(file), node)
+}
+
+func (p *Pipeline) annotateDefinition(at positioner, node *yaml.Node) {
+	node = documentContent(node)
+
+	if runs := mappingValue(node, "runs"); runs != nil {
+		p.runsPos = at(runs)
+		switch {
+		case runs.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+			// The script starts on the line after the block indicator,
+			// at an indentation we don't know.
+			p.runsPos.Line++
+			p.runsPos.Column = 0
+		case runs.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0:
+			p.runsPos.Column++
+		}
+	}
+
+	children := mappingValue(node, "pipeline")
+	for i := range p.Pipeline {
+		p.Pipeline[i].annotate(at, sequenceItem(children, i))
+	}
+}
+
+func annotatePipelines(at positioner, node *yaml.Node, ps []Pipeline) {
+	for i := range ps {
+		ps[i].annotate(at, sequenceItem(node, i))
+	}
+}
+
+// annotatePositions records the positions of every pipeline in the
+// configuration, given the YAML document it was decoded from. This has to
+// happen before subpackage ranges are expanded, so that indexes match.
+func (cfg *Configuration) annotatePositions() {
+	node := documentContent(cfg.tree)
+	at := cfg.nodePosition
+
+	annotatePipelines(at, mappingValue(node, "pipeline"), cfg.Pipeline)
+
+	if cfg.Test != nil {
+		annotatePipelines(at, mappingValue(mappingValue(node, "test"), "pipeline"), cfg.Test.Pipeline)
+	}
+
+	subpackages := mappingValue(node, "subpackages")
+	for i := range cfg.Subpackages {
+		sp := &cfg.Subpackages[i]
+		spNode := sequenceItem(subpackages, i)
+
+		annotatePipelines(at, mappingValue(spNode, "pipeline"), sp.Pipeline)
+		if sp.Test != nil {
+			annotatePipelines(at, mappingValue(mappingValue(spNode, "test"), "pipeline"), sp.Test.Pipeline)
+		}
+	}
+}
+
+// annotateSubpackages records the position of every subpackage, once ranges
+// are expanded into as many subpackages as their data has items.
+func (cfg *Configuration) annotateSubpackages(datas map[string]DataItems, in []Subpackage) {
+	subpackages := mappingValue(documentContent(cfg.tree), "subpackages")
+
+	cfg.subpackagePos = nil
+	for i, sp := range in {
+		pos := cfg.nodePosition(sequenceItem(subpackages, i))
+		if name := mappingValue(sequenceItem(subpackages, i), "name"); name != nil {
+			pos = cfg.nodePosition(name)
+		}
+
+		n := 1
+		if sp.Range != "" {
+			n = len(datas[sp.Range])
+		}
+		for range n {
+			cfg.subpackagePos = append(cfg.subpackagePos, pos)
+		}
+	}
+}
+
+// position returns the position of the node found by following keys from the
+// top of the configuration, or of its deepest ancestor that exists.
+func (cfg Configuration) position(keys ...string) Position {
+	node := documentContent(cfg.tree)
+	if node == nil {
+		return Position{}
+	}
+	for _, k := range keys {
+		v := mappingValue(node, k)
+		if v == nil {
+			break
+		}
+		node = v
+	}
+	return cfg.nodePosition(node)
+}
+
+// subpackageKeyPosition returns the position of key in the i-th subpackage as
+// written in the configuration, before ranges are expanded.
+func (cfg Configuration) subpackageKeyPosition(i int, key string) Position {
+	sp := sequenceItem(mappingValue(documentContent(cfg.tree), "subpackages"), i)
+	if v := mappingValue(sp, key); v != nil {
+		return cfg.nodePosition(v)
+	}
+	if sp == nil {
+		return Position{}
+	}
+	return cfg.nodePosition(sp)
+}
+
+// subpackagePosition returns the position of the i-th subpackage.
+func (cfg Configuration) subpackagePosition(i int) Position {
+	if i < 0 || i >= len(cfg.subpackagePos) {
+		return cfg.position("subpackages")
+	}
+	return cfg.subpackagePos[i]
+}
+
+// PackagePosition returns the position of the package or subpackage named
+// name, if known.
+func (cfg Configuration) PackagePosition(name string) Position {
+	if name == cfg.Package.Name {
+		return cfg.position("package", "name")
+	}
+	for i, sp := range cfg.Subpackages {
+		if sp.Name == name {
+			return cfg.subpackagePosition(i)
+		}
+	}
+	return Position{}
+}
+
+var yamlLineRegexp = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+// syntaxErrorPosition returns the position of a YAML syntax error.
+func syntaxErrorPosition(file string, err error) Position {
+	m := yamlLineRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		return Position{}
+	}
+	line, _ := strconv.Atoi(m[1])
+	return Position{File: file, Line: line}
+}
+
+// decodeError fixes up an error from decoding remarshaled, the configuration
+// as marshaled back from root, so that its line numbers refer to root.
+func decodeError(at positioner, root *yaml.Node, remarshaled []byte, err error) error {
+	var terr *yaml.TypeError
+	if !errors.As(err, &terr) {
+		return err
+	}
+
+	var node yaml.Node
+	if yaml.Unmarshal(remarshaled, &node) != nil {
+		return err
+	}
+
+	var pos Position
+	fixed := &yaml.TypeError{}
+	for _, msg := range terr.Errors {
+		if m := yamlLineRegexp.FindStringSubmatchIndex(msg); m != nil {
+			line, _ := strconv.Atoi(msg[m[2]:m[3]])
+			if orig := correspondingNode(&node, root, line); orig != nil {
+				if pos.IsZero() {
+					pos = at(orig)
+				}
+				msg = fmt.Sprintf("line %d: %s", orig.Line, msg[m[1]:])
+			}
+		}
+		fixed.Errors = append(fixed.Errors, msg)
+	}
+
+	return WithPosition(pos, fixed)
+}
+
+// correspondingNode returns the node of orig at the same place as the first,
+// most nested, node of remarshaled on line. Both trees must have the same
+// shape.
+func correspondingNode(remarshaled, orig *yaml.Node, line int) *yaml.Node {
+	if remarshaled == nil || orig == nil || len(remarshaled.Content) != len(orig.Content) {
+		return nil
+	}
+	for i := range remarshaled.Content {
+		if n := correspondingNode(remarshaled.Content[i], orig.Content[i], line); n != nil {
+			return n
+		}
+	}
+	if remarshaled.Line == line && remarshaled.Kind != yaml.DocumentNode {
+		return orig
+	}
+	return nil
+}
@@ -43,10 +43,21 @@
 	// Optional: disable these linters that are not enabled by
 	// default.
 	disabled?: [...string]
+
+	// Optional: the hardening properties the hardening linter
+	// requires of the
+	// binaries in the package: pie, relro, bindnow, nx,
+	// stack-protector and
+	// fortify. Defaults to pie, relro, bindnow and nx.
+	hardening?: [...string]
 })
 
 // Configuration is the root melange configuration.
 #Configuration: close({
+	// Optional: Fragment files to merge into this configuration, relative to
+	// it. They are merged when the configuration is parsed, leaving this empty.
+	include?: [...string]
+
 	// Package metadata
 	package!: #Package
 
@@ -346,6 +357,11 @@
 
 	// Optional: Mark this package as not providing any executables
 	"no-commands"?: bool
+
+	// Optional: Shared objects this package loads with dlopen, which
+	// are added
+	// as runtime dependencies
+	dlopen?: [...string]
 })
 
 #PathMutation: close({
@@ -33,6 +33,7 @@ import (
 	"gopkg.in/ini.v1"
 
 	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter/linters"
 	"chainguard.dev/melange/pkg/linter/types"
 )
 
@@ -44,32 +45,11 @@ func LintAPK(ctx context.Context, path string, require, warn []string, outputDir
 		return err
 	}
 
-	var r io.Reader
-	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
-		if err != nil {
-			return fmt.Errorf("creating HTTP request: %w", err)
-		}
-		if err := auth.DefaultAuthenticators.AddAuth(ctx, req); err != nil {
-			return fmt.Errorf("adding authentication to request: %w", err)
-		}
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return fmt.Errorf("getting apk %q: %w", path, err)
-		}
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("getting apk %q: %s", path, resp.Status)
-		}
-		defer resp.Body.Close()
-		r = resp.Body
-	} else {
-		file, err := os.Open(path)
-		if err != nil {
-			return fmt.Errorf("linting apk %q: %w", path, err)
-		}
-		defer file.Close()
-		r = file
+	r, err := openAPK(ctx, path)
+	if err != nil {
+		return fmt.Errorf("linting apk %q: %w", path, err)
 	}
+	defer r.Close()
 
 	exp, err := expandapk.ExpandApk(ctx, r, "")
 	if err != nil {
@@ -129,6 +109,14 @@ func LintAPK(ctx context.Context, path string, require, warn []string, outputDir
 
 	log.Infof("linting apk: %s (size: %s)", pkgname, humanize.Bytes(uint64(exp.Size)))
 
+	// The dependencies of a built package are final.
+	info := parsePkgInfo(data)
+	ctx = linters.WithDependencies(ctx, linters.Dependencies{
+		Runtime:  info["depend"],
+		Provides: info["provides"],
+	})
+	ctx = withPrevious(ctx, pkgname, pkgver)
+
 	// map of pkgname -> lint results
 	results := make(map[string]*types.PackageLintResults)
 
@@ -138,6 +126,10 @@ func LintAPK(ctx context.Context, path string, require, warn []string, outputDir
 	// Run required linters - logs directly, returns errors
 	lintErr := lintPackageFS(ctx, cfg, pkgname, exp.TarFS, require, results, fullPackageName)
 
+	if sarif := sarifFromContext(ctx); sarif != nil {
+		sarif.add(cfg, results, require)
+	}
+
 	// Save lint results to JSON file if outputDir is provided and there are findings
 	if outputDir != "" && len(results) > 0 {
 		log.Infof("saving %d package lint result(s) to %s", len(results), filepath.Join(outputDir, arch))
@@ -151,6 +143,30 @@ func LintAPK(ctx context.Context, path string, require, warn []string, outputDir
 	return lintErr
 }
 
+// openAPK opens the APK at path, which can be a URL.
+func openAPK(ctx context.Context, path string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		return os.Open(path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating HTTP request: %w", err)
+	}
+	if err := auth.DefaultAuthenticators.AddAuth(ctx, req); err != nil {
+		return nil, fmt.Errorf("adding authentication to request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("getting %q: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("getting %q: %s", path, resp.Status)
+	}
+	return resp.Body, nil
+}
+
 func parseMelangeYaml(fsys fs.FS) (*config.Configuration, error) {
 	my, err := fsys.Open(".melange.yaml")
 	if err != nil {
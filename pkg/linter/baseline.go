@@ -0,0 +1,318 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linter
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/chainguard-dev/clog"
+	"gopkg.in/yaml.v3"
+
+	"chainguard.dev/melange/pkg/linter/linters"
+	"chainguard.dev/melange/pkg/linter/types"
+)
+
+// Suppression accepts the finding of a linter for a path of a package.
+type Suppression struct {
+	Linter  string `yaml:"linter"`
+	Package string `yaml:"package"`
+	// Path is empty for the findings of linters which don't report paths.
+	Path          string `yaml:"path,omitempty"`
+	Justification string `yaml:"justification"`
+}
+
+func (s Suppression) String() string {
+	if s.Path == "" {
+		return fmt.Sprintf("%s: %s", s.Package, s.Linter)
+	}
+	return fmt.Sprintf("%s: %s: %s", s.Package, s.Linter, s.Path)
+}
+
+type suppressionKey struct {
+	linter, pkg, path string
+}
+
+// Baseline is a set of accepted lint findings, so that only new findings are
+// reported. It is safe for concurrent use.
+type Baseline struct {
+	Suppressions []Suppression `yaml:"suppressions"`
+
+	mu sync.Mutex
+	// record adds the findings linted to the baseline instead of
+	// suppressing them.
+	record bool
+	// used are the suppressions which matched a finding.
+	used map[suppressionKey]bool
+	// linted are the packages and linters which ran.
+	linted map[suppressionKey]bool
+}
+
+// LoadBaseline reads the baseline at path. Every suppression needs a
+// justification.
+func LoadBaseline(path string) (*Baseline, error) {
+	b, err := readBaseline(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, s := range b.Suppressions {
+		if err := checkLinters([]string{s.Linter}); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s, err))
+		}
+		if strings.TrimSpace(s.Justification) == "" {
+			errs = append(errs, fmt.Errorf("%s: missing justification", s))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid lint baseline %s: %w", path, err)
+	}
+
+	return b, nil
+}
+
+func readBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading lint baseline: %w", err)
+	}
+
+	b := &Baseline{}
+	if err := yaml.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("parsing lint baseline %s: %w", path, err)
+	}
+
+	return b, nil
+}
+
+// NewBaselineRecorder returns a baseline which records the findings linted
+// with it, to be written to path. The justifications of the baseline already
+// at path, if any, are kept, and new suppressions need one added.
+func NewBaselineRecorder(path string) (*Baseline, error) {
+	b := &Baseline{}
+	if _, err := os.Stat(path); err == nil {
+		if b, err = readBaseline(path); err != nil {
+			return nil, err
+		}
+	}
+	b.record = true
+	return b, nil
+}
+
+// Write writes the suppressions which matched findings to path, sorted.
+// Suppressions of packages or linters which didn't run are kept.
+func (b *Baseline) Write(path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	suppressions := slices.DeleteFunc(slices.Clone(b.Suppressions), func(s Suppression) bool {
+		return b.linted[suppressionKey{s.Linter, s.Package, ""}] && !b.used[s.key()]
+	})
+	slices.SortFunc(suppressions, func(a, b Suppression) int {
+		return cmp.Or(
+			strings.Compare(a.Package, b.Package),
+			strings.Compare(a.Linter, b.Linter),
+			strings.Compare(a.Path, b.Path),
+		)
+	})
+
+	data, err := yaml.Marshal(&Baseline{Suppressions: suppressions})
+	if err != nil {
+		return err
+	}
+
+	// #nosec G306 - The baseline is checked in with the configuration
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (s Suppression) key() suppressionKey {
+	return suppressionKey{s.Linter, s.Package, s.Path}
+}
+
+// suppresses reports whether the baseline accepts the finding of linterName
+// for all of paths in pkgname, marking the suppressions it used. It returns
+// the paths which aren't suppressed.
+func (b *Baseline) suppresses(pkgname, linterName string, paths []string) (bool, []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.used == nil {
+		b.used = map[suppressionKey]bool{}
+	}
+
+	if len(paths) == 0 {
+		paths = []string{""}
+	}
+
+	var unsuppressed []string
+	for _, path := range paths {
+		key := suppressionKey{linterName, pkgname, path}
+		if !slices.ContainsFunc(b.Suppressions, func(s Suppression) bool { return s.key() == key }) {
+			if !b.record {
+				unsuppressed = append(unsuppressed, path)
+				continue
+			}
+			b.Suppressions = append(b.Suppressions, Suppression{Linter: linterName, Package: pkgname, Path: path})
+		}
+		b.used[key] = true
+	}
+
+	return len(unsuppressed) == 0, unsuppressed
+}
+
+// ran records that linterName linted pkgname, so unused suppressions for it
+// are stale.
+func (b *Baseline) ran(pkgname, linterName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.linted == nil {
+		b.linted = map[suppressionKey]bool{}
+	}
+	b.linted[suppressionKey{linterName, pkgname, ""}] = true
+}
+
+// Stale returns the suppressions which matched no finding of a linter that
+// ran on their package.
+func (b *Baseline) Stale() []Suppression {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var stale []Suppression
+	for _, s := range b.Suppressions {
+		if b.linted[suppressionKey{s.Linter, s.Package, ""}] && !b.used[s.key()] {
+			stale = append(stale, s)
+		}
+	}
+	return stale
+}
+
+// ReportStale warns about the stale suppressions of the baseline.
+func (b *Baseline) ReportStale(ctx context.Context) {
+	log := clog.FromContext(ctx)
+	for _, s := range b.Stale() {
+		log.Warnf("stale lint baseline entry, nothing to suppress: %s", s)
+	}
+}
+
+type baselineKey struct{}
+
+// WithBaseline returns a context which lints with the baseline b.
+func WithBaseline(ctx context.Context, b *Baseline) context.Context {
+	return context.WithValue(ctx, baselineKey{}, b)
+}
+
+func baselineFromContext(ctx context.Context) *Baseline {
+	b, _ := ctx.Value(baselineKey{}).(*Baseline)
+	return b
+}
+
+// findingPaths returns the paths, or other subjects like packages, which the
+// details of a finding report, for the baseline to match them. It reports
+// whether the paths are known: the details of unknown types could be about
+// any number of paths, so their findings are never suppressed.
+func findingPaths(details any) ([]string, bool) {
+	var paths []string
+
+	switch d := details.(type) {
+	case nil:
+		// Findings without details are about the package as a whole.
+	case *types.DuplicateFilesDetails:
+		for _, dup := range d.Duplicates {
+			paths = append(paths, dup.Paths...)
+		}
+	case *types.NonLinuxDetails:
+		for _, ref := range d.References {
+			paths = append(paths, ref.Path)
+		}
+	case *types.UnsupportedArchDetails:
+		for _, file := range d.Files {
+			paths = append(paths, file.Path)
+		}
+	case *types.BinaryArchDetails:
+		for _, bin := range d.Binaries {
+			paths = append(paths, bin.Path)
+		}
+	case *types.SpecialPermissionsDetails:
+		for _, file := range d.Files {
+			paths = append(paths, file.Path)
+		}
+	case *types.WorldWriteableDetails:
+		for _, file := range d.Files {
+			paths = append(paths, file.Path)
+		}
+	case *types.UsrMergeDetails:
+		paths = append(paths, d.Paths...)
+	case *types.HardeningDetails:
+		for _, bin := range d.Binaries {
+			paths = append(paths, bin.Path)
+		}
+	case *types.UnresolvedSonameDetails:
+		for _, so := range d.Sonames {
+			paths = append(paths, so.Binaries...)
+		}
+	case *types.SymlinkDetails:
+		for _, link := range d.Symlinks {
+			paths = append(paths, link.Path)
+		}
+	case *types.LeakDetails:
+		for _, leak := range d.Leaks {
+			paths = append(paths, leak.Path)
+		}
+	case *types.ABIDetails:
+		for _, lib := range d.Libraries {
+			if lib.Change != linters.ABICompatible {
+				paths = append(paths, lib.Path)
+			}
+		}
+	case *types.RegressionDetails:
+		paths = append(paths, d.Added...)
+		paths = append(paths, d.Removed...)
+		for _, bin := range d.Resized {
+			paths = append(paths, bin.Path)
+		}
+		for _, file := range d.Changed {
+			paths = append(paths, file.Path)
+		}
+		paths = append(paths, d.LostCommands...)
+	case *types.UnstrippedBinaryDetails:
+		paths = append(paths, d.Binaries...)
+	case *types.PythonMultipleDetails:
+		paths = append(paths, d.Packages...)
+	case *types.PathListDetails:
+		paths = append(paths, d.Paths...)
+	case map[string]any:
+		// The details of external linters are only known to report paths
+		// the way PathListDetails does.
+		list, _ := d["paths"].([]any)
+		for _, p := range list {
+			if p, ok := p.(string); ok {
+				paths = append(paths, p)
+			}
+		}
+	default:
+		return nil, false
+	}
+
+	slices.Sort(paths)
+	return slices.Compact(paths), true
+}
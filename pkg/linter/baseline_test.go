@@ -0,0 +1,206 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	apkofs "chainguard.dev/apko/pkg/apk/fs"
+	"github.com/chainguard-dev/clog/slogtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter/types"
+)
+
+func TestBaseline(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "usr", "local", "bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "usr", "local", "bin", "foo"), []byte("foo"), 0o755))
+	fsys := apkofs.DirFS(ctx, dir)
+
+	cfg := &config.Configuration{Package: config.Package{Name: "foo", Version: "1.0", Epoch: 0}}
+
+	baselinePath := filepath.Join(t.TempDir(), "lint-baseline.yaml")
+	require.NoError(t, os.WriteFile(baselinePath, []byte(`suppressions:
+  - linter: usrlocal
+    package: foo
+    path: usr/local/bin/foo
+    justification: upstream hardcodes /usr/local/bin/foo
+  - linter: usrlocal
+    package: foo
+    path: usr/local/bin/gone
+    justification: no longer installed
+  - linter: opt
+    package: foo
+    path: opt/foo
+    justification: the opt linter doesn't run
+`), 0o644))
+
+	baseline, err := LoadBaseline(baselinePath)
+	require.NoError(t, err)
+
+	// The accepted finding doesn't fail.
+	bctx := WithBaseline(ctx, baseline)
+	assert.NoError(t, LintBuild(bctx, cfg, "foo", []string{"usrlocal"}, nil, fsys, "", ""))
+	assert.Equal(t, []Suppression{{
+		Linter:        "usrlocal",
+		Package:       "foo",
+		Path:          "usr/local/bin/gone",
+		Justification: "no longer installed",
+	}}, baseline.Stale())
+
+	// A new path does.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "usr", "local", "bin", "bar"), []byte("bar"), 0o755))
+	fsys = apkofs.DirFS(ctx, dir)
+	err = LintBuild(bctx, cfg, "foo", []string{"usrlocal"}, nil, fsys, "", "")
+	assert.ErrorContains(t, err, "not in lint baseline: usr/local/bin/bar")
+
+	// Recording keeps the justifications, adds the new paths, and drops the
+	// stale entries.
+	recorder, err := NewBaselineRecorder(baselinePath)
+	require.NoError(t, err)
+	assert.NoError(t, LintBuild(WithBaseline(ctx, recorder), cfg, "foo", []string{"usrlocal"}, nil, fsys, "", ""))
+	require.NoError(t, recorder.Write(baselinePath))
+
+	written, err := readBaseline(baselinePath)
+	require.NoError(t, err)
+	assert.Equal(t, []Suppression{{
+		Linter:        "opt",
+		Package:       "foo",
+		Path:          "opt/foo",
+		Justification: "the opt linter doesn't run",
+	}, {
+		Linter:  "usrlocal",
+		Package: "foo",
+		Path:    "usr/local/bin/bar",
+	}, {
+		Linter:        "usrlocal",
+		Package:       "foo",
+		Path:          "usr/local/bin/foo",
+		Justification: "upstream hardcodes /usr/local/bin/foo",
+	}}, written.Suppressions)
+
+	// The new entry needs a justification.
+	_, err = LoadBaseline(baselinePath)
+	assert.ErrorContains(t, err, "foo: usrlocal: usr/local/bin/bar: missing justification")
+}
+
+func TestBaselinePartialSuppression(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	// Two dangling symlinks are one finding of the symlinks linter.
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "usr", "bin"), 0o755))
+	require.NoError(t, os.Symlink("foo-1", filepath.Join(dir, "usr", "bin", "foo")))
+	require.NoError(t, os.Symlink("bar-1", filepath.Join(dir, "usr", "bin", "bar")))
+	fsys := apkofs.DirFS(ctx, dir)
+
+	cfg := &config.Configuration{Package: config.Package{Name: "foo", Version: "1.0", Epoch: 0}}
+	baseline := &Baseline{Suppressions: []Suppression{{
+		Linter:        "symlinks",
+		Package:       "foo",
+		Path:          "usr/bin/foo",
+		Justification: "foo-1 is installed by foo-libs",
+	}}}
+	bctx := WithBaseline(ctx, baseline)
+
+	err := LintBuild(bctx, cfg, "foo", []string{"symlinks"}, nil, fsys, "", "")
+	assert.ErrorContains(t, err, "not in lint baseline: usr/bin/bar")
+	assert.NotContains(t, err.Error(), "not in lint baseline: usr/bin/foo")
+
+	// Suppressing both paths suppresses the finding.
+	baseline.Suppressions = append(baseline.Suppressions, Suppression{
+		Linter:        "symlinks",
+		Package:       "foo",
+		Path:          "usr/bin/bar",
+		Justification: "bar-1 is installed by foo-libs",
+	})
+	assert.NoError(t, LintBuild(bctx, cfg, "foo", []string{"symlinks"}, nil, fsys, "", ""))
+	assert.Empty(t, baseline.Stale())
+}
+
+func TestFindingPaths(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		details any
+		want    []string
+		unknown bool
+	}{{
+		name: "no details",
+	}, {
+		name: "duplicates",
+		details: &types.DuplicateFilesDetails{Duplicates: []*types.DuplicateFileInfo{
+			{Paths: []string{"usr/share/b", "usr/share/a"}},
+			{Paths: []string{"usr/share/c"}},
+		}},
+		want: []string{"usr/share/a", "usr/share/b", "usr/share/c"},
+	}, {
+		name:    "special permissions",
+		details: &types.SpecialPermissionsDetails{Files: []types.FilePermissionInfo{{Path: "usr/bin/su"}}},
+		want:    []string{"usr/bin/su"},
+	}, {
+		name:    "hardening",
+		details: &types.HardeningDetails{Binaries: []types.HardeningInfo{{Path: "usr/bin/foo"}, {Path: "usr/bin/bar"}}},
+		want:    []string{"usr/bin/bar", "usr/bin/foo"},
+	}, {
+		name: "unresolved sonames",
+		details: &types.UnresolvedSonameDetails{Sonames: []types.UnresolvedSoname{
+			{Soname: "libfoo.so.1", Binaries: []string{"usr/bin/foo", "usr/bin/bar"}},
+			{Soname: "libbar.so.1", Binaries: []string{"usr/bin/bar"}},
+		}},
+		want: []string{"usr/bin/bar", "usr/bin/foo"},
+	}, {
+		name: "abi",
+		details: &types.ABIDetails{Libraries: []types.ABIChange{
+			{Path: "usr/lib/libfoo.so.2", Change: "soname-bump"},
+			{Path: "usr/lib/libbar.so.1", Change: "compatible"},
+		}},
+		want: []string{"usr/lib/libfoo.so.2"},
+	}, {
+		name: "regressions",
+		details: &types.RegressionDetails{
+			Added:        []string{"usr/bin/new"},
+			Removed:      []string{"usr/bin/old"},
+			Resized:      []types.ResizedBinary{{Path: "usr/bin/foo"}},
+			Changed:      []types.ChangedFile{{Path: "etc/foo"}},
+			LostCommands: []string{"cmd:old"},
+		},
+		want: []string{"cmd:old", "etc/foo", "usr/bin/foo", "usr/bin/new", "usr/bin/old"},
+	}, {
+		name:    "path list",
+		details: &types.PathListDetails{Paths: []string{"usr/local/bin/foo"}},
+		want:    []string{"usr/local/bin/foo"},
+	}, {
+		name:    "external",
+		details: map[string]any{"paths": []any{"usr/lib/libfoo.a", 42}},
+		want:    []string{"usr/lib/libfoo.a"},
+	}, {
+		name:    "unknown",
+		details: &struct{ Paths []string }{Paths: []string{"usr/bin/foo"}},
+		unknown: true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, ok := findingPaths(tt.details)
+			assert.Equal(t, !tt.unknown, ok)
+			assert.Equal(t, tt.want, paths)
+		})
+	}
+}
@@ -17,28 +17,38 @@ package linter
 import (
 	"context"
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
 
 	apkofs "chainguard.dev/apko/pkg/apk/fs"
 	"github.com/chainguard-dev/clog"
 
 	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter/linters"
 	"chainguard.dev/melange/pkg/linter/types"
 )
 
 // Lint the given build directory at the given path
-// Lint results will be stored as JSON in the packages directory
+// If outputDir is provided, lint results will be stored as JSON in it
 func LintBuild(ctx context.Context, cfg *config.Configuration, packageName string, require, warn []string, fsys apkofs.FullFS, outputDir, arch string) error {
 	if err := checkLinters(append(require, warn...)); err != nil {
 		return err
 	}
 
+	// The linters needing the generated dependencies run in LintDependencies.
+	require, warn = dependencyLinters(require, false), dependencyLinters(warn, false)
+
 	// map of pkgname -> lint results
 	results := make(map[string]*types.PackageLintResults)
 
 	log := clog.FromContext(ctx)
 	log.Infof("linting apk: %s", packageName)
 
+	if cfg != nil {
+		ctx = withPrevious(ctx, packageName, fmt.Sprintf("%s-r%d", cfg.Package.Version, cfg.Package.Epoch))
+	}
+
 	// Construct full package name with version and epoch
 	var fullPackageName string
 	if cfg != nil {
@@ -53,14 +63,75 @@ func LintBuild(ctx context.Context, cfg *config.Configuration, packageName strin
 	// Run required linters - logs directly, returns errors
 	lintErr := lintPackageFS(ctx, cfg, packageName, fsys, require, results, fullPackageName)
 
-	// Save lint results to JSON file if there are any findings
-	if len(results) > 0 {
+	if sarif := sarifFromContext(ctx); sarif != nil {
+		sarif.add(cfg, results, require)
+	}
+
+	// Save lint results to JSON file if outputDir is provided and there are findings
+	if outputDir != "" && len(results) > 0 {
 		log.Infof("saving %d package lint result(s) to %s", len(results), filepath.Join(outputDir, arch))
 		if err := saveLintResults(ctx, cfg, results, outputDir, arch); err != nil {
 			log.Warnf("failed to save lint results: %v", err)
 		}
-	} else {
+	} else if outputDir != "" {
 		log.Infof("no lint findings to persist for package %s", packageName)
+
+		// Don't leave the findings of a previous build for LintDependencies
+		// to add to.
+		if cfg != nil {
+			if err := os.Remove(lintResultsPath(cfg, packageName, outputDir, arch)); err != nil && !os.IsNotExist(err) {
+				log.Warnf("failed to remove stale lint results: %v", err)
+			}
+		}
+	}
+
+	return lintErr
+}
+
+// LintDependencies runs the linters which need the generated dependencies of
+// the package, once they are known. If outputDir is provided, its findings are
+// added to the lint results persisted by LintBuild.
+func LintDependencies(ctx context.Context, cfg *config.Configuration, packageName string, require, warn []string, fsys fs.FS, deps linters.Dependencies, outputDir, arch string) error {
+	if err := checkLinters(append(require, warn...)); err != nil {
+		return err
+	}
+
+	require, warn = dependencyLinters(require, true), dependencyLinters(warn, true)
+	if len(require)+len(warn) == 0 {
+		return nil
+	}
+
+	log := clog.FromContext(ctx)
+	log.Infof("linting dependencies of apk: %s", packageName)
+
+	ctx = linters.WithDependencies(ctx, deps)
+	if cfg != nil {
+		ctx = withPrevious(ctx, packageName, fmt.Sprintf("%s-r%d", cfg.Package.Version, cfg.Package.Epoch))
+	}
+
+	var fullPackageName string
+	if cfg != nil {
+		fullPackageName = fmt.Sprintf("%s-%s-r%d", packageName, cfg.Package.Version, cfg.Package.Epoch)
+	} else {
+		fullPackageName = packageName
+	}
+
+	results := make(map[string]*types.PackageLintResults)
+
+	_ = lintPackageFS(ctx, cfg, packageName, fsys, warn, results, fullPackageName)
+	lintErr := lintPackageFS(ctx, cfg, packageName, fsys, require, results, fullPackageName)
+
+	if sarif := sarifFromContext(ctx); sarif != nil {
+		sarif.add(cfg, results, require)
+	}
+
+	if outputDir != "" && len(results) > 0 {
+		if err := mergeLintResults(cfg, results, outputDir, arch); err != nil {
+			log.Warnf("failed to load lint results: %v", err)
+		}
+		if err := saveLintResults(ctx, cfg, results, outputDir, arch); err != nil {
+			log.Warnf("failed to save lint results: %v", err)
+		}
 	}
 
 	return lintErr
@@ -0,0 +1,232 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	apkofs "chainguard.dev/apko/pkg/apk/fs"
+	"github.com/chainguard-dev/clog"
+
+	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter/types"
+)
+
+// RegisterExternalLinters adds the executables in dir as linters named after
+// them, to be enabled with the builtin ones. It must be called before linting.
+//
+// An external linter is run with the path of the package's filesystem as its
+// argument and a JSON types.PackageDescription on stdin. It prints a JSON
+// array of types.LinterFinding, if it finds anything, and fails only if it
+// couldn't lint the package.
+func RegisterExternalLinters(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("reading external linters: %w", err)
+	}
+
+	var errs []error
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		// Follow symlinks to the executables.
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("reading external linter %s: %w", name, err)
+		}
+		if !info.Mode().IsRegular() || info.Mode()&0o111 == 0 {
+			continue
+		}
+
+		if strings.Contains(name, ",") {
+			errs = append(errs, fmt.Errorf("external linter %q: name can't contain a comma", name))
+			continue
+		}
+		if _, found := linterMap[name]; found {
+			errs = append(errs, fmt.Errorf("external linter %q: a linter of that name already exists", name))
+			continue
+		}
+
+		linterMap[name] = linter{
+			LinterFunc:      externalLinter(filepath.Join(dir, name)),
+			defaultBehavior: Ignore,
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+type packageDirKey struct{}
+
+// WithPackageDir returns a context which gives external linters the directory
+// of the package filesystem being linted, rather than a copy of it. The modes,
+// owners and xattrs of its files are those on disk, which can differ from the
+// filesystem the builtin linters are given.
+func WithPackageDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, packageDirKey{}, dir)
+}
+
+func packageDirFromContext(ctx context.Context) (string, bool) {
+	dir, ok := ctx.Value(packageDirKey{}).(string)
+	return dir, ok && dir != ""
+}
+
+// packageDescription returns the description of pkgname given to external
+// linters.
+func packageDescription(cfg *config.Configuration, pkgname string) *types.PackageDescription {
+	desc := &types.PackageDescription{Name: pkgname}
+	if cfg == nil {
+		return desc
+	}
+
+	desc.Origin = cfg.Package.Name
+	desc.Version = cfg.Package.Version
+	desc.Epoch = cfg.Package.Epoch
+
+	deps := cfg.Package.Dependencies
+	desc.Description = cfg.Package.Description
+	for _, sp := range cfg.Subpackages {
+		if sp.Name == pkgname {
+			deps = sp.Dependencies
+			desc.Description = sp.Description
+		}
+	}
+	desc.Runtime = deps.Runtime
+	desc.Provides = deps.Provides
+
+	return desc
+}
+
+// copyPackageFS writes the files, directories and symlinks of fsys to dir,
+// with only the permission bits of their modes.
+func copyPackageFS(fsys fs.FS, dir string) error {
+	return fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(path))
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0o755)
+		case d.Type()&fs.ModeSymlink != 0:
+			rl, ok := fsys.(apkofs.ReadLinkFS)
+			if !ok {
+				return nil
+			}
+			link, err := rl.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			src, err := fsys.Open(path)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+			dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(dst, src); err != nil {
+				dst.Close()
+				return err
+			}
+			return dst.Close()
+		}
+
+		// Device nodes and the like can't be created unprivileged.
+		return nil
+	})
+}
+
+// externalLinter returns a linter which runs the executable at path.
+func externalLinter(path string) linterFunc {
+	return func(ctx context.Context, cfg *config.Configuration, pkgname string, fsys fs.FS) error {
+		log := clog.FromContext(ctx)
+
+		dir, ok := packageDirFromContext(ctx)
+		if !ok {
+			tmp, err := os.MkdirTemp("", "melange-lint-")
+			if err != nil {
+				return fmt.Errorf("creating directory for external linter: %w", err)
+			}
+			defer os.RemoveAll(tmp)
+			if err := copyPackageFS(fsys, tmp); err != nil {
+				return fmt.Errorf("copying %s for external linter: %w", pkgname, err)
+			}
+			dir = tmp
+		}
+
+		desc, err := json.Marshal(packageDescription(cfg, pkgname))
+		if err != nil {
+			return fmt.Errorf("marshaling package description: %w", err)
+		}
+
+		var stdout, stderr bytes.Buffer
+		// #nosec G204 - External linters are configured by the user
+		cmd := exec.CommandContext(ctx, path, dir)
+		cmd.Stdin = bytes.NewReader(desc)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		runErr := cmd.Run()
+
+		for _, line := range strings.Split(strings.TrimSpace(stderr.String()), "\n") {
+			if line != "" {
+				log.Debugf("%s: %s", filepath.Base(path), line)
+			}
+		}
+		if runErr != nil {
+			return fmt.Errorf("running external linter %s: %w", path, runErr)
+		}
+
+		if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+			return nil
+		}
+
+		var findings []*types.LinterFinding
+		if err := json.Unmarshal(stdout.Bytes(), &findings); err != nil {
+			return fmt.Errorf("parsing findings of external linter %s: %w", path, err)
+		}
+		for _, f := range findings {
+			if f == nil || f.Message == "" {
+				return fmt.Errorf("external linter %s reported a finding without a message", path)
+			}
+		}
+		if len(findings) == 0 {
+			return nil
+		}
+
+		return &types.FindingsError{Findings: findings}
+	}
+}
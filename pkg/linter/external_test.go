@@ -0,0 +1,112 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apkofs "chainguard.dev/apko/pkg/apk/fs"
+	"github.com/chainguard-dev/clog/slogtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter/types"
+)
+
+func TestExternalLinters(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	out := t.TempDir()
+	lintersDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(lintersDir, "no-foo"), []byte(`#!/bin/sh
+cat > `+out+`/desc.json
+if [ -e "$1/usr/bin/foo" ]; then
+	echo '[{"message": "foo is installed", "details": {"paths": ["usr/bin/foo"]}}, {"message": "foo is still installed", "explain": "Remove foo"}]'
+fi
+`), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(lintersDir, "broken"), []byte("#!/bin/sh\nexit 1\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(lintersDir, "README"), []byte("not a linter"), 0o644))
+	t.Cleanup(func() {
+		delete(linterMap, "no-foo")
+		delete(linterMap, "broken")
+	})
+
+	require.NoError(t, RegisterExternalLinters(lintersDir))
+	assert.Contains(t, linterMap, "no-foo")
+	assert.NotContains(t, linterMap, "README")
+	assert.NotContains(t, DefaultWarnLinters(), "no-foo")
+
+	// External linters can't be registered twice, or shadow builtins.
+	assert.Error(t, RegisterExternalLinters(lintersDir))
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "usr", "bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "usr", "bin", "foo"), []byte("foo"), 0o755))
+	fsys := apkofs.DirFS(ctx, dir)
+
+	cfg := &config.Configuration{
+		Package: config.Package{Name: "foo", Version: "1.0", Epoch: 2},
+		Subpackages: []config.Subpackage{{
+			Name:         "foo-bin",
+			Dependencies: config.Dependencies{Runtime: []string{"bar"}},
+		}},
+	}
+
+	// Without the package's directory, a copy of it is linted.
+	for _, name := range []string{"copy", "package dir"} {
+		t.Run(name, func(t *testing.T) {
+			lctx := ctx
+			if name == "package dir" {
+				lctx = WithPackageDir(ctx, dir)
+			}
+
+			results := map[string]*types.PackageLintResults{}
+			err := lintPackageFS(lctx, cfg, "foo-bin", fsys, []string{"no-foo"}, results, "foo-bin-1.0-r2")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "foo is still installed")
+
+			findings := results["foo-bin"].Findings["no-foo"]
+			require.Len(t, findings, 2)
+			assert.Equal(t, "foo is installed", findings[0].Message)
+			paths, ok := findingPaths(findings[0].Details)
+			assert.True(t, ok)
+			assert.Equal(t, []string{"usr/bin/foo"}, paths)
+			assert.Equal(t, "Remove foo", findings[1].Explain)
+
+			data, err := os.ReadFile(filepath.Join(out, "desc.json"))
+			require.NoError(t, err)
+			var desc types.PackageDescription
+			require.NoError(t, json.Unmarshal(data, &desc))
+			assert.Equal(t, types.PackageDescription{
+				Name:    "foo-bin",
+				Origin:  "foo",
+				Version: "1.0",
+				Epoch:   2,
+				Runtime: []string{"bar"},
+			}, desc)
+		})
+	}
+
+	// Packages without findings pass.
+	require.NoError(t, os.Remove(filepath.Join(dir, "usr", "bin", "foo")))
+	assert.NoError(t, lintPackageFS(ctx, cfg, "foo-bin", apkofs.DirFS(ctx, dir), []string{"no-foo"}, map[string]*types.PackageLintResults{}, "foo-bin-1.0-r2"))
+
+	// A linter which fails to run is a finding.
+	assert.Error(t, lintPackageFS(ctx, cfg, "foo-bin", fsys, []string{"broken"}, map[string]*types.PackageLintResults{}, "foo-bin-1.0-r2"))
+}
@@ -24,29 +24,86 @@ import (
 	"github.com/chainguard-dev/clog"
 
 	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter/linters"
 	"chainguard.dev/melange/pkg/linter/types"
 )
 
-func lintPackageFS(ctx context.Context, cfg *config.Configuration, pkgname string, fsys fs.FS, linters []string, results map[string]*types.PackageLintResults, fullPackageName string) error {
+// packageResults returns the lint results of pkgname, adding them to results
+// if there are none yet.
+func packageResults(results map[string]*types.PackageLintResults, pkgname, fullPackageName string) *types.PackageLintResults {
+	if _, ok := results[pkgname]; !ok {
+		results[pkgname] = &types.PackageLintResults{
+			PackageName: fullPackageName,
+			Findings:    make(map[string][]*types.LinterFinding),
+		}
+	}
+	return results[pkgname]
+}
+
+func lintPackageFS(ctx context.Context, cfg *config.Configuration, pkgname string, fsys fs.FS, linterNames []string, results map[string]*types.PackageLintResults, fullPackageName string) error {
 	log := clog.FromContext(ctx)
+	baseline := baselineFromContext(ctx)
 	var errs []error
 
-	for _, linterName := range linters {
+	for _, linterName := range linterNames {
 		if err := ctx.Err(); err != nil {
 			return err
 		}
 		linter := linterMap[linterName]
-		if err := linter.LinterFunc(ctx, cfg, pkgname, fsys); err != nil {
-			// Extract message and structured details if available
-			var message string
-			var details any
-
-			structErr := &types.StructuredError{}
-			if errors.As(err, &structErr) {
-				message = structErr.Message
-				details = structErr.Details
-			} else {
-				message = err.Error()
+		// What the linter reports is kept whether or not it is a finding.
+		lctx := linters.WithReporter(ctx, func(r *types.LinterFinding) {
+			pkgResults := packageResults(results, pkgname, fullPackageName)
+			if pkgResults.Reports == nil {
+				pkgResults.Reports = map[string]*types.LinterFinding{}
+			}
+			pkgResults.Reports[linterName] = r
+		})
+		err := linter.LinterFunc(lctx, cfg, pkgname, fsys)
+		if errors.Is(err, linters.ErrNotChecked) {
+			log.Warnf("[%s] %v", linterName, err)
+			errs = append(errs, fmt.Errorf("linter %q did not run: %w", linterName, err))
+			continue
+		}
+		// Suppressions of linters which didn't check the package aren't stale.
+		if baseline != nil {
+			baseline.ran(pkgname, linterName)
+		}
+		if err == nil {
+			continue
+		}
+
+		// Extract messages and structured details if available
+		var findings []*types.LinterFinding
+		findingsErr := &types.FindingsError{}
+		structErr := &types.StructuredError{}
+		switch {
+		case errors.As(err, &findingsErr):
+			findings = findingsErr.Findings
+		case errors.As(err, &structErr):
+			findings = []*types.LinterFinding{{Message: structErr.Message, Details: structErr.Details}}
+		default:
+			findings = []*types.LinterFinding{{Message: err.Error()}}
+		}
+
+		for _, f := range findings {
+			message, details := f.Message, f.Details
+			findingErr := err
+			if len(findings) > 1 {
+				findingErr = errors.New(message)
+			}
+
+			// Only findings the baseline doesn't accept count
+			if baseline != nil {
+				paths, ok := findingPaths(details)
+				if !ok {
+					log.Warnf("[%s] can't match %T with the lint baseline", linterName, details)
+				} else if suppressed, unsuppressed := baseline.suppresses(pkgname, linterName, paths); suppressed {
+					log.Infof("[%s] suppressed by lint baseline: %s", linterName, strings.SplitN(message, "\n", 2)[0])
+					continue
+				} else if len(unsuppressed) < len(paths) {
+					message = fmt.Sprintf("%s\nnot in lint baseline: %s", message, strings.Join(unsuppressed, ", "))
+					findingErr = fmt.Errorf("%w (not in lint baseline: %s)", findingErr, strings.Join(unsuppressed, ", "))
+				}
 			}
 
 			// Split message into lines for better console readability
@@ -60,30 +117,26 @@ func lintPackageFS(ctx context.Context, cfg *config.Configuration, pkgname strin
 				}
 			}
 
-			// Initialize package results
-			if _, ok := results[pkgname]; !ok {
-				results[pkgname] = &types.PackageLintResults{
-					PackageName: fullPackageName,
-					Findings:    make(map[string][]*types.LinterFinding),
-				}
-			}
-
 			// Append finding to the linter's findings list
 			finding := &types.LinterFinding{
 				Message: messageLines[0], // Use first line as the summary message
+				Explain: f.Explain,
 				Details: details,
 			}
-			if linter.Explain != "" {
-				log.Warnf("  → %s", linter.Explain)
+			if finding.Explain == "" {
 				finding.Explain = linter.Explain
 			}
+			if finding.Explain != "" {
+				log.Warnf("  → %s", finding.Explain)
+			}
 
 			// Display itemized findings for structured details
 			logStructuredDetails(log, details)
 
-			results[pkgname].Findings[linterName] = append(results[pkgname].Findings[linterName], finding)
+			pkgResults := packageResults(results, pkgname, fullPackageName)
+			pkgResults.Findings[linterName] = append(pkgResults.Findings[linterName], finding)
 
-			errs = append(errs, fmt.Errorf("linter %q failed: %w", linterName, err))
+			errs = append(errs, fmt.Errorf("linter %q failed: %w", linterName, findingErr))
 		}
 	}
 
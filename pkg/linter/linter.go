@@ -33,6 +33,9 @@ type linter struct {
 	LinterFunc      linterFunc
 	Explain         string
 	defaultBehavior defaultBehavior
+	// needsDependencies linters run once the dependencies of the package
+	// have been generated, by LintDependencies rather than LintBuild.
+	needsDependencies bool
 }
 
 type defaultBehavior int
@@ -59,6 +62,11 @@ func DefaultWarnLinters() []string {
 }
 
 var linterMap = map[string]linter{
+	"abi": {
+		LinterFunc:      linters.ABILinter,
+		Explain:         "Rebuild the reverse dependencies of the shared libraries whose soname changed or which removed symbols, or restore their ABI",
+		defaultBehavior: Warn,
+	},
 	"dev": {
 		LinterFunc:      linters.DevLinter,
 		Explain:         "If this package is creating /dev nodes, it should use udev instead; otherwise, remove any files in /dev",
@@ -194,6 +202,33 @@ var linterMap = map[string]linter{
 		Explain:         "This package contains binaries compiled for unsupported architectures (only aarch64/arm64 and amd64/x86_64 binaries are supported)",
 		defaultBehavior: Warn,
 	},
+	"hardening": {
+		LinterFunc:      linters.HardeningLinter,
+		Explain:         "Build binaries with the hardening flags of the toolchain (e.g. -fPIE -pie, -Wl,-z,relro,-z,now, -fstack-protector-strong, -D_FORTIFY_SOURCE), or list the properties this package can meet in checks.hardening",
+		defaultBehavior: Warn,
+	},
+	"leaks": {
+		LinterFunc:      linters.LeaksLinter,
+		Explain:         "Remove build paths from files (e.g. with -trimpath or -ffile-prefix-map) and never package secrets",
+		defaultBehavior: Warn,
+	},
+	"regressions": {
+		LinterFunc:        linters.RegressionsLinter,
+		Explain:           "Make sure the files, binary sizes, modes, owners and commands which changed since the previous version changed on purpose",
+		defaultBehavior:   Warn,
+		needsDependencies: true,
+	},
+	"sonames": {
+		LinterFunc:        linters.SonameLinter,
+		Explain:           "Add a runtime dependency on the package providing these shared libraries, or vendor them in this package",
+		defaultBehavior:   Warn,
+		needsDependencies: true,
+	},
+	"symlinks": {
+		LinterFunc:      linters.SymlinksLinter,
+		Explain:         "Make symlinks point at files installed by this package or the packages built with it, using relative or absolute paths outside the build workspace",
+		defaultBehavior: Warn,
+	},
 	"staticarchive": {
 		LinterFunc:      linters.StaticArchiveLinter,
 		Explain:         "This package contains static archives (.a files)",
@@ -206,6 +241,14 @@ var linterMap = map[string]linter{
 	},
 }
 
+// dependencyLinters returns the linters of names which need the generated
+// dependencies of the package if needs is true, or the others otherwise.
+func dependencyLinters(names []string, needs bool) []string {
+	return slices.DeleteFunc(slices.Clone(names), func(name string) bool {
+		return linterMap[name].needsDependencies != needs
+	})
+}
+
 func checkLinters(linters []string) error {
 	var errs []error
 	for _, linterName := range linters {
@@ -0,0 +1,326 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linters
+
+import (
+	"bytes"
+	"context"
+	"debug/elf"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+
+	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter/types"
+)
+
+const (
+	// ABICompatible libraries only added symbols, or are new.
+	ABICompatible = "compatible"
+	// ABIRemovedSymbols libraries kept their soname but no longer export
+	// some symbols.
+	ABIRemovedSymbols = "removed-symbols"
+	// ABISonameBump libraries changed their soname, so everything linked
+	// against them needs to be rebuilt.
+	ABISonameBump = "soname-bump"
+	// ABIRemovedLibrary libraries are no longer installed.
+	ABIRemovedLibrary = "removed-library"
+)
+
+// sharedLibrary is the ABI of a shared library.
+type sharedLibrary struct {
+	path    string
+	soname  string
+	exports map[string]bool
+}
+
+// libraryKey returns what identifies a library across sonames: its name
+// without the .so suffix and version, like libfoo for libfoo.so.1.
+func libraryKey(soname string) string {
+	if i := strings.Index(soname, ".so"); i > 0 {
+		return soname[:i]
+	}
+	return soname
+}
+
+// exportedSymbols returns the versioned names of the dynamic symbols the ELF
+// file defines for other objects to use.
+func exportedSymbols(elfFile *elf.File) (map[string]bool, error) {
+	syms, err := elfFile.DynamicSymbols()
+	if err != nil {
+		return nil, err
+	}
+
+	exports := map[string]bool{}
+	for _, sym := range syms {
+		if sym.Section == elf.SHN_UNDEF || sym.Name == "" {
+			continue
+		}
+		switch elf.ST_BIND(sym.Info) {
+		case elf.STB_GLOBAL, elf.STB_WEAK:
+		default:
+			continue
+		}
+		switch elf.ST_VISIBILITY(sym.Other) {
+		case elf.STV_DEFAULT, elf.STV_PROTECTED:
+		default:
+			continue
+		}
+		switch elf.ST_TYPE(sym.Info) {
+		case elf.STT_FUNC, elf.STT_OBJECT, elf.STT_TLS, elf.STT_COMMON, elf.STT_GNU_IFUNC:
+		default:
+			continue
+		}
+
+		name := sym.Name
+		if sym.Version != "" {
+			name = fmt.Sprintf("%s@%s", sym.Name, sym.Version)
+		}
+		exports[name] = true
+	}
+	return exports, nil
+}
+
+// readSharedLibrary returns the ABI of the file at p, if it is a shared
+// library.
+func readSharedLibrary(fsys fs.FS, p string) (*sharedLibrary, error) {
+	f, err := fsys.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	readerAt, ok := f.(io.ReaderAt)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+		readerAt = bytes.NewReader(data)
+	}
+
+	hdr := make([]byte, len(ElfMagic))
+	if _, err := readerAt.ReadAt(hdr, 0); err != nil || !bytes.Equal(ElfMagic, hdr) {
+		return nil, nil
+	}
+
+	elfFile, err := elf.NewFile(readerAt)
+	if err != nil {
+		return nil, nil
+	}
+	defer elfFile.Close()
+
+	if elfFile.Type != elf.ET_DYN {
+		return nil, nil
+	}
+
+	lib := &sharedLibrary{path: p, soname: path.Base(p)}
+	if sonames, err := elfFile.DynString(elf.DT_SONAME); err == nil && len(sonames) > 0 {
+		lib.soname = sonames[0]
+	}
+	if lib.exports, err = exportedSymbols(elfFile); err != nil {
+		return nil, fmt.Errorf("reading dynamic symbols of %s: %w", p, err)
+	}
+
+	return lib, nil
+}
+
+// sharedLibraries returns the shared libraries in fsys by soname.
+func sharedLibraries(ctx context.Context, fsys fs.FS) (map[string]*sharedLibrary, error) {
+	libs := map[string]*sharedLibrary{}
+
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if IsIgnoredPath(p) {
+			return nil
+		}
+
+		// The symlinks to libraries are what is linked against, not what
+		// is loaded.
+		if !d.Type().IsRegular() || !IsSharedObjectFileRegex.MatchString(path.Base(p)) {
+			return nil
+		}
+
+		lib, err := readSharedLibrary(fsys, p)
+		if err != nil {
+			return err
+		}
+		if lib != nil {
+			libs[lib.soname] = lib
+		}
+
+		return nil
+	})
+
+	return libs, err
+}
+
+// symbolChanges returns the symbols only in b, and only in a.
+func symbolChanges(a, b map[string]bool) (added, removed []string) {
+	for sym := range b {
+		if !a[sym] {
+			added = append(added, sym)
+		}
+	}
+	for sym := range a {
+		if !b[sym] {
+			removed = append(removed, sym)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	return added, removed
+}
+
+func ABILinter(ctx context.Context, _ *config.Configuration, pkgname string, fsys fs.FS) error {
+	log := clog.FromContext(ctx)
+
+	prev, ok := previousFromContext(ctx)
+	if !ok {
+		log.Debugf("no previous version of %s, skipping ABI check", pkgname)
+		return nil
+	}
+
+	prevLibs, err := sharedLibraries(ctx, prev.FS)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", prev.Package, err)
+	}
+	curLibs, err := sharedLibraries(ctx, fsys)
+	if err != nil {
+		return err
+	}
+	if len(prevLibs) == 0 && len(curLibs) == 0 {
+		return nil
+	}
+
+	// Libraries are matched by soname, and the ones whose soname is new
+	// replace a previous library of the same name with another version.
+	replaced := map[string]*sharedLibrary{}
+	unmatched := map[string][]*sharedLibrary{}
+	for _, soname := range slices.Sorted(maps.Keys(prevLibs)) {
+		if _, ok := curLibs[soname]; !ok {
+			key := libraryKey(soname)
+			unmatched[key] = append(unmatched[key], prevLibs[soname])
+		}
+	}
+	for _, soname := range slices.Sorted(maps.Keys(curLibs)) {
+		if _, ok := prevLibs[soname]; ok {
+			continue
+		}
+		key := libraryKey(soname)
+		if libs := unmatched[key]; len(libs) > 0 {
+			replaced[soname], unmatched[key] = libs[0], libs[1:]
+		}
+	}
+
+	changes := []types.ABIChange{}
+	var broken []string
+	for _, soname := range slices.Sorted(maps.Keys(curLibs)) {
+		cur := curLibs[soname]
+		p, ok := prevLibs[soname]
+		if !ok {
+			p, ok = replaced[soname]
+		}
+		if !ok {
+			changes = append(changes, types.ABIChange{
+				Path:      cur.path,
+				NewSoname: cur.soname,
+				Change:    ABICompatible,
+			})
+			continue
+		}
+
+		added, removed := symbolChanges(p.exports, cur.exports)
+		change := types.ABIChange{
+			Path:      cur.path,
+			OldSoname: p.soname,
+			NewSoname: cur.soname,
+			Added:     added,
+			Removed:   removed,
+		}
+		switch {
+		case p.soname != cur.soname:
+			change.Change = ABISonameBump
+			broken = append(broken, fmt.Sprintf("%s -> %s", p.soname, cur.soname))
+		case len(removed) > 0:
+			change.Change = ABIRemovedSymbols
+			symbolWord := "symbol"
+			if len(removed) > 1 {
+				symbolWord = "symbols"
+			}
+			broken = append(broken, fmt.Sprintf("%s lost %d %s", cur.soname, len(removed), symbolWord))
+		case len(added) > 0:
+			change.Change = ABICompatible
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(unmatched)) {
+		for _, p := range unmatched[key] {
+			changes = append(changes, types.ABIChange{
+				Path:      p.path,
+				OldSoname: p.soname,
+				Change:    ABIRemovedLibrary,
+			})
+			broken = append(broken, fmt.Sprintf("%s removed", p.soname))
+		}
+	}
+
+	for _, c := range changes {
+		if c.Change == ABICompatible {
+			log.Infof("%s: compatible ABI change since %s, %d added symbols", c.Path, prev.Package, len(c.Added))
+		}
+	}
+
+	// The changes are reported even when they are all compatible, only
+	// breaking ones are findings.
+	details := &types.ABIDetails{
+		Previous:  prev.Package,
+		Libraries: changes,
+	}
+
+	if len(broken) == 0 {
+		libraryWord := "library"
+		if len(changes) != 1 {
+			libraryWord = "libraries"
+		}
+		report(ctx, &types.LinterFinding{
+			Message: fmt.Sprintf("%s changed the ABI of %d shared %s compatibly since %s", pkgname, len(changes), libraryWord, prev.Package),
+			Details: details,
+		})
+		return nil
+	}
+
+	libraryWord := "library"
+	if len(broken) > 1 {
+		libraryWord = "libraries"
+	}
+	message := fmt.Sprintf("%s breaks the ABI of %d shared %s since %s (%s)", pkgname, len(broken), libraryWord, prev.Package, strings.Join(broken, ", "))
+	report(ctx, &types.LinterFinding{Message: message, Details: details})
+	return types.NewStructuredError(message, details)
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linters
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+
+	"chainguard.dev/melange/pkg/linter/types"
+)
+
+// Dependencies are the final dependencies of the package being linted, and
+// how to resolve them. They are only known once the dependencies have been
+// generated, so the linters which need them are skipped without them.
+type Dependencies struct {
+	// Runtime and Provides are the declared and generated dependencies of
+	// the package.
+	Runtime  []string
+	Provides []string
+
+	// Resolver resolves runtime dependencies against the repositories.
+	Resolver *apk.PkgResolver
+}
+
+// ErrNotChecked is returned by the linters which can't check a package
+// without something the context doesn't have, like a resolver for its
+// dependencies. It isn't a finding, but fails the linter if it is required.
+var ErrNotChecked = errors.New("not checked")
+
+type dependenciesKey struct{}
+
+// WithDependencies returns a context which makes deps available to the
+// linters which need them.
+func WithDependencies(ctx context.Context, deps Dependencies) context.Context {
+	return context.WithValue(ctx, dependenciesKey{}, deps)
+}
+
+func dependenciesFromContext(ctx context.Context) (Dependencies, bool) {
+	deps, ok := ctx.Value(dependenciesKey{}).(Dependencies)
+	return deps, ok
+}
+
+type siblingsKey struct{}
+
+// WithSiblings returns a context which makes the filesystems of the other
+// packages built from the same configuration, keyed by package name,
+// available to the linters. They are not in the repositories yet, so this is
+// the only way to know what they contain.
+func WithSiblings(ctx context.Context, siblings map[string]fs.FS) context.Context {
+	return context.WithValue(ctx, siblingsKey{}, siblings)
+}
+
+func siblingsFromContext(ctx context.Context) map[string]fs.FS {
+	siblings, _ := ctx.Value(siblingsKey{}).(map[string]fs.FS)
+	return siblings
+}
+
+// Previous is the previous version of the package being linted, to find what
+// changed since.
+type Previous struct {
+	// Package is the name and version of the previous package, like
+	// foo-1.2.3-r0.
+	Package string
+	FS      fs.FS
+	// Provides are the provides of the previous package.
+	Provides []string
+}
+
+type previousKey struct{}
+
+// WithPrevious returns a context which makes the previous version of the
+// package available to the linters.
+func WithPrevious(ctx context.Context, prev Previous) context.Context {
+	return context.WithValue(ctx, previousKey{}, prev)
+}
+
+func previousFromContext(ctx context.Context) (Previous, bool) {
+	prev, ok := ctx.Value(previousKey{}).(Previous)
+	return prev, ok
+}
+
+type reporterKey struct{}
+
+// WithReporter returns a context in which linters pass what they found out
+// about the package to fn, whether or not it is a finding, like how the ABI
+// of its libraries changed.
+func WithReporter(ctx context.Context, fn func(*types.LinterFinding)) context.Context {
+	return context.WithValue(ctx, reporterKey{}, fn)
+}
+
+func report(ctx context.Context, r *types.LinterFinding) {
+	if fn, _ := ctx.Value(reporterKey{}).(func(*types.LinterFinding)); fn != nil {
+		fn(r)
+	}
+}
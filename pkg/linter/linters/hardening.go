@@ -0,0 +1,273 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linters
+
+import (
+	"bytes"
+	"context"
+	"debug/elf"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter/types"
+)
+
+// The hardening properties binaries can be required to have.
+const (
+	HardeningPIE            = "pie"
+	HardeningRELRO          = "relro"
+	HardeningBindNow        = "bindnow"
+	HardeningNX             = "nx"
+	HardeningStackProtector = "stack-protector"
+	HardeningFortify        = "fortify"
+)
+
+var hardeningProperties = []string{
+	HardeningPIE,
+	HardeningRELRO,
+	HardeningBindNow,
+	HardeningNX,
+	HardeningStackProtector,
+	HardeningFortify,
+}
+
+// DefaultHardening are the properties required of binaries when the package
+// doesn't list any in checks.hardening. Whether a binary has stack-protector
+// or FORTIFY symbols depends on its code as well as on how it was built, so
+// they must be asked for.
+var DefaultHardening = []string{
+	HardeningPIE,
+	HardeningRELRO,
+	HardeningBindNow,
+	HardeningNX,
+}
+
+// packageChecks returns the checks configured for the package or subpackage
+// named pkgname.
+func packageChecks(cfg *config.Configuration, pkgname string) config.Checks {
+	if cfg == nil {
+		return config.Checks{}
+	}
+	if cfg.Package.Name == pkgname {
+		return cfg.Package.Checks
+	}
+	for _, sp := range cfg.Subpackages {
+		if sp.Name == pkgname {
+			return sp.Checks
+		}
+	}
+	return config.Checks{}
+}
+
+// elfHardening returns the hardening properties of an ELF file.
+func elfHardening(path string, f *elf.File) types.HardeningInfo {
+	info := types.HardeningInfo{
+		Path: path,
+		// Shared libraries and PIEs are both ET_DYN.
+		PIE:   f.Type == elf.ET_DYN,
+		RELRO: "none",
+	}
+
+	hasRELRO, hasStack := false, false
+	for _, prog := range f.Progs {
+		switch prog.Type {
+		case elf.PT_GNU_RELRO:
+			hasRELRO = true
+		case elf.PT_GNU_STACK:
+			hasStack = true
+			info.NX = prog.Flags&elf.PF_X == 0
+		}
+	}
+	// Without a PT_GNU_STACK header the stack is executable.
+	if !hasStack {
+		info.NX = false
+	}
+
+	if vals, _ := f.DynValue(elf.DT_BIND_NOW); len(vals) > 0 {
+		info.BindNow = true
+	}
+	if vals, _ := f.DynValue(elf.DT_FLAGS); len(vals) > 0 && vals[0]&uint64(elf.DF_BIND_NOW) != 0 {
+		info.BindNow = true
+	}
+	if vals, _ := f.DynValue(elf.DT_FLAGS_1); len(vals) > 0 && vals[0]&uint64(elf.DF_1_NOW) != 0 {
+		info.BindNow = true
+	}
+
+	if hasRELRO {
+		info.RELRO = "partial"
+		if info.BindNow {
+			info.RELRO = "full"
+		}
+	}
+
+	var symbols []elf.Symbol
+	if syms, err := f.DynamicSymbols(); err == nil {
+		symbols = append(symbols, syms...)
+	}
+	if syms, err := f.Symbols(); err == nil {
+		symbols = append(symbols, syms...)
+	}
+	for _, sym := range symbols {
+		switch {
+		case sym.Name == "__stack_chk_fail" || sym.Name == "__stack_chk_guard":
+			info.StackProtector = true
+		case strings.HasPrefix(sym.Name, "__") && strings.HasSuffix(sym.Name, "_chk"):
+			info.Fortify = true
+		}
+	}
+
+	return info
+}
+
+// isGoBinary reports whether f was built by the Go toolchain, which has its
+// own stack checks and no FORTIFY.
+func isGoBinary(f *elf.File) bool {
+	return f.Section(".go.buildinfo") != nil || f.Section(".note.go.buildid") != nil
+}
+
+// missingHardening returns the required properties info lacks.
+func missingHardening(info types.HardeningInfo, required []string, goBinary bool) []string {
+	var missing []string
+	for _, prop := range required {
+		var ok bool
+		switch prop {
+		case HardeningPIE:
+			ok = info.PIE
+		case HardeningRELRO:
+			ok = info.RELRO != "none"
+		case HardeningBindNow:
+			ok = info.BindNow
+		case HardeningNX:
+			ok = info.NX
+		case HardeningStackProtector:
+			ok = info.StackProtector || goBinary
+		case HardeningFortify:
+			ok = info.Fortify || goBinary
+		}
+		if !ok {
+			missing = append(missing, prop)
+		}
+	}
+	return missing
+}
+
+func HardeningLinter(ctx context.Context, cfg *config.Configuration, pkgname string, fsys fs.FS) error {
+	required := packageChecks(cfg, pkgname).Hardening
+	if len(required) == 0 {
+		required = DefaultHardening
+	}
+	for _, prop := range required {
+		if !slices.Contains(hardeningProperties, prop) {
+			return fmt.Errorf("unknown hardening property %q in checks.hardening, want one of %s", prop, strings.Join(hardeningProperties, ", "))
+		}
+	}
+
+	var binaries []types.HardeningInfo
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if IsIgnoredPath(path) {
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if info.Size() < int64(len(ElfMagic)) {
+			return nil
+		}
+
+		ext := filepath.Ext(path)
+		mode := info.Mode()
+		if mode&0o111 == 0 && !IsObjectFileRegex.MatchString(ext) {
+			return nil
+		}
+
+		f, err := fsys.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+
+		readerAt, ok := f.(io.ReaderAt)
+		if !ok {
+			return nil
+		}
+
+		hdr := make([]byte, len(ElfMagic))
+		if _, err := readerAt.ReadAt(hdr, 0); err != nil {
+			return nil
+		}
+
+		if !bytes.Equal(ElfMagic, hdr) {
+			return nil
+		}
+
+		elfFile, err := elf.NewFile(readerAt)
+		if err != nil {
+			return nil
+		}
+		defer elfFile.Close()
+
+		// Only executables and shared libraries are linked with hardening
+		// flags, not relocatable objects.
+		if elfFile.Type != elf.ET_EXEC && elfFile.Type != elf.ET_DYN {
+			return nil
+		}
+
+		hardening := elfHardening(path, elfFile)
+		hardening.Missing = missingHardening(hardening, required, isGoBinary(elfFile))
+		if len(hardening.Missing) > 0 {
+			binaries = append(binaries, hardening)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if len(binaries) > 0 {
+		details := &types.HardeningDetails{
+			Binaries: binaries,
+		}
+
+		binaryWord := "binary"
+		if len(binaries) > 1 {
+			binaryWord = "binaries"
+		}
+		message := fmt.Sprintf("%s contains %d %s missing required hardening (%s)", pkgname, len(binaries), binaryWord, strings.Join(required, ", "))
+		return types.NewStructuredError(message, details)
+	}
+
+	return nil
+}
@@ -0,0 +1,231 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linters
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"slices"
+
+	"github.com/chainguard-dev/clog"
+
+	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter/types"
+)
+
+// melangeCache is where the melange cache is mounted during builds.
+const melangeCache = "/var/cache/melange"
+
+// maxLeakScanSize is the size of the largest file scanned for leaks.
+const maxLeakScanSize = 64 << 20
+
+// minStringLength is the length of the shortest printable string extracted
+// from a file, like strings(1).
+const minStringLength = 4
+
+const (
+	LeakBuildPath    = "build-path"
+	LeakPrivateKey   = "private-key"
+	LeakAWSAccessKey = "aws-access-key"
+	LeakNetrc        = "netrc-password"
+)
+
+// secretPatterns match secrets, after a cheap check for the literals of
+// which each match contains one.
+var secretPatterns = []struct {
+	kind     string
+	literals [][]byte
+	re       *regexp.Regexp
+}{{
+	kind:     LeakPrivateKey,
+	literals: [][]byte{[]byte("PRIVATE KEY-----")},
+	re:       regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |ENCRYPTED )?PRIVATE KEY-----`),
+}, {
+	kind:     LeakAWSAccessKey,
+	literals: [][]byte{[]byte("AKIA"), []byte("ASIA")},
+	re:       regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+}, {
+	kind:     LeakNetrc,
+	literals: [][]byte{[]byte("password")},
+	re:       regexp.MustCompile(`\bmachine\s+\S+\s+(?:login\s+\S+\s+)?password\s+\S+`),
+}}
+
+type buildPathsKey struct{}
+
+// WithBuildPaths returns a context which adds the paths of the host the
+// package was built on, like its workspace, to what the leaks linter looks
+// for.
+func WithBuildPaths(ctx context.Context, paths []string) context.Context {
+	return context.WithValue(ctx, buildPathsKey{}, paths)
+}
+
+func buildPathsFromContext(ctx context.Context) []string {
+	paths := []string{buildWorkspace, melangeCache}
+	extra, _ := ctx.Value(buildPathsKey{}).([]string)
+	for _, p := range extra {
+		// Relative paths are too likely to match unrelated strings.
+		if len(p) > 1 && p[0] == '/' {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// printableStrings calls fn with each run of printable ASCII in data, and its
+// offset, so that binaries are scanned like text.
+func printableStrings(data []byte, fn func(s []byte, offset int)) {
+	start := -1
+	for i, c := range data {
+		printable := c >= 0x20 && c < 0x7f || c == '\t' || c == '\n' || c == '\r'
+		if printable {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start >= minStringLength {
+			fn(data[start:i], start)
+		}
+		start = -1
+	}
+	if start >= 0 && len(data)-start >= minStringLength {
+		fn(data[start:], start)
+	}
+}
+
+// findLeaks returns the leaks in data, with the offset of the first
+// occurrence of each kind of leak and how often it occurs.
+func findLeaks(path string, data []byte, buildPaths []string) []types.LeakInfo {
+	var leaks []types.LeakInfo
+	found := map[string]int{}
+
+	add := func(kind, match string, offset int) {
+		if i, ok := found[kind+"\x00"+match]; ok {
+			leaks[i].Count++
+			return
+		}
+		found[kind+"\x00"+match] = len(leaks)
+		leaks = append(leaks, types.LeakInfo{
+			Path:   path,
+			Kind:   kind,
+			Match:  match,
+			Offset: int64(offset),
+			Count:  1,
+		})
+	}
+
+	printableStrings(data, func(s []byte, offset int) {
+		for _, p := range buildPaths {
+			for i, rest := 0, s; ; {
+				j := bytes.Index(rest, []byte(p))
+				if j < 0 {
+					break
+				}
+				add(LeakBuildPath, p, offset+i+j)
+				i += j + len(p)
+				rest = rest[j+len(p):]
+			}
+		}
+
+		for _, sp := range secretPatterns {
+			if !slices.ContainsFunc(sp.literals, func(lit []byte) bool {
+				return bytes.Contains(s, lit)
+			}) {
+				continue
+			}
+			for _, loc := range sp.re.FindAllIndex(s, -1) {
+				// Don't repeat the secret in the findings.
+				add(sp.kind, "", offset+loc[0])
+			}
+		}
+	})
+
+	return leaks
+}
+
+func LeaksLinter(ctx context.Context, _ *config.Configuration, pkgname string, fsys fs.FS) error {
+	log := clog.FromContext(ctx)
+	buildPaths := buildPathsFromContext(ctx)
+
+	var leaks []types.LeakInfo
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if IsIgnoredPath(path) {
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if info.Size() > maxLeakScanSize {
+			log.Debugf("not scanning %s for leaks, it is larger than %d bytes", path, maxLeakScanSize)
+			return nil
+		}
+
+		f, err := fsys.Open(path)
+		if err != nil {
+			return fmt.Errorf("opening %s: %w", path, err)
+		}
+		defer f.Close()
+
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+
+		leaks = append(leaks, findLeaks(path, data, buildPaths)...)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(leaks) > 0 {
+		details := &types.LeakDetails{
+			Leaks: leaks,
+		}
+
+		files := map[string]bool{}
+		for _, l := range leaks {
+			files[l.Path] = true
+		}
+
+		fileWord := "file"
+		if len(files) > 1 {
+			fileWord = "files"
+		}
+		message := fmt.Sprintf("%s contains %d %s leaking build paths or secrets", pkgname, len(files), fileWord)
+		return types.NewStructuredError(message, details)
+	}
+
+	return nil
+}
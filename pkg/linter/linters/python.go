@@ -58,13 +58,18 @@ func PythonDocsLinter(_ context.Context, _ *config.Configuration, _ string, fsys
 		return err
 	}
 
+	var paths []string
 	for _, m := range packages {
 		base := filepath.Base(m)
 		if base == "doc" || base == "docs" {
-			return fmt.Errorf("docs directory encountered in Python site-packages directory")
+			paths = append(paths, m)
 		}
 	}
 
+	if len(paths) > 0 {
+		return types.NewStructuredError("docs directory encountered in Python site-packages directory", &types.PathListDetails{Paths: paths})
+	}
+
 	return nil
 }
 
@@ -139,12 +144,17 @@ func PythonTestLinter(_ context.Context, _ *config.Configuration, _ string, fsys
 		return err
 	}
 
+	var paths []string
 	for _, m := range packages {
 		base := filepath.Base(m)
 		if base == "test" || base == "tests" {
-			return fmt.Errorf("tests directory encountered in Python site-packages directory")
+			paths = append(paths, m)
 		}
 	}
 
+	if len(paths) > 0 {
+		return types.NewStructuredError("tests directory encountered in Python site-packages directory", &types.PathListDetails{Paths: paths})
+	}
+
 	return nil
 }
@@ -0,0 +1,222 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linters
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+
+	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter/types"
+)
+
+// binarySizeThreshold is how much a binary can grow or shrink, relative to
+// its previous size, before it is reported.
+const binarySizeThreshold = 0.5
+
+// fileState is what is compared of a file between versions of a package.
+type fileState struct {
+	mode     fs.FileMode
+	owner    string
+	size     int64
+	isBinary bool
+}
+
+func (s fileState) String() string {
+	if s.owner == "" {
+		return s.mode.String()
+	}
+	return fmt.Sprintf("%s %s", s.mode, s.owner)
+}
+
+// isELF reports whether the file at path starts with the ELF magic.
+func isELF(fsys fs.FS, path string) bool {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	hdr := make([]byte, len(ElfMagic))
+	if _, err := io.ReadFull(f, hdr); err != nil {
+		return false
+	}
+	return bytes.Equal(ElfMagic, hdr)
+}
+
+// packageFiles returns the state of every path in fsys.
+func packageFiles(ctx context.Context, fsys fs.FS) (map[string]fileState, error) {
+	files := map[string]fileState{}
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if path == "." || IsIgnoredPath(path) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		state := fileState{mode: info.Mode(), size: info.Size()}
+		if hdr, ok := info.Sys().(*tar.Header); ok {
+			state.owner = fmt.Sprintf("%d:%d", hdr.Uid, hdr.Gid)
+		}
+		if info.Mode().IsRegular() {
+			state.isBinary = isELF(fsys, path)
+		}
+		files[path] = state
+
+		return nil
+	})
+
+	return files, err
+}
+
+// commands returns the names of the cmd: provides.
+func commands(provides []string) map[string]bool {
+	cmds := map[string]bool{}
+	for _, p := range provides {
+		if name := dependencyName(p); strings.HasPrefix(name, "cmd:") {
+			cmds[name] = true
+		}
+	}
+	return cmds
+}
+
+// resized reports whether a binary changed size by more than the threshold.
+func resized(prev, cur fileState) bool {
+	if !prev.isBinary && !cur.isBinary || prev.size == 0 {
+		return false
+	}
+	delta := float64(cur.size-prev.size) / float64(prev.size)
+	return delta > binarySizeThreshold || delta < -binarySizeThreshold
+}
+
+// countOf formats n of something, in the singular or plural.
+func countOf(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+func RegressionsLinter(ctx context.Context, _ *config.Configuration, pkgname string, fsys fs.FS) error {
+	log := clog.FromContext(ctx)
+
+	prev, ok := previousFromContext(ctx)
+	if !ok {
+		log.Debugf("no previous version of %s, skipping regression check", pkgname)
+		return nil
+	}
+
+	prevFiles, err := packageFiles(ctx, prev.FS)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", prev.Package, err)
+	}
+	curFiles, err := packageFiles(ctx, fsys)
+	if err != nil {
+		return err
+	}
+
+	details := &types.RegressionDetails{Previous: prev.Package}
+
+	for _, path := range slices.Sorted(maps.Keys(curFiles)) {
+		cur := curFiles[path]
+		p, existed := prevFiles[path]
+		if !existed {
+			if !cur.mode.IsDir() {
+				details.Added = append(details.Added, path)
+			}
+			continue
+		}
+
+		if resized(p, cur) {
+			details.Resized = append(details.Resized, types.ResizedBinary{
+				Path:    path,
+				OldSize: p.size,
+				NewSize: cur.size,
+			})
+		}
+
+		// The owner is unknown for filesystems without tar headers.
+		if p.owner == "" || cur.owner == "" {
+			p.owner, cur.owner = "", ""
+		}
+		if p.mode != cur.mode || p.owner != cur.owner {
+			details.Changed = append(details.Changed, types.ChangedFile{
+				Path: path,
+				Old:  p.String(),
+				New:  cur.String(),
+			})
+		}
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(prevFiles)) {
+		if _, ok := curFiles[path]; !ok && !prevFiles[path].mode.IsDir() {
+			details.Removed = append(details.Removed, path)
+		}
+	}
+
+	// The provides of the package are only known once its dependencies have
+	// been generated.
+	if deps, ok := dependenciesFromContext(ctx); ok {
+		curCmds := commands(deps.Provides)
+		for _, cmd := range slices.Sorted(maps.Keys(commands(prev.Provides))) {
+			if !curCmds[cmd] {
+				details.LostCommands = append(details.LostCommands, cmd)
+			}
+		}
+	}
+
+	var changes []string
+	if n := len(details.Added); n > 0 {
+		changes = append(changes, countOf(n, "file", "files")+" added")
+	}
+	if n := len(details.Removed); n > 0 {
+		changes = append(changes, countOf(n, "file", "files")+" removed")
+	}
+	if n := len(details.Resized); n > 0 {
+		changes = append(changes, countOf(n, "binary", "binaries")+" resized")
+	}
+	if n := len(details.Changed); n > 0 {
+		changes = append(changes, countOf(n, "mode or owner", "modes or owners")+" changed")
+	}
+	if len(details.LostCommands) > 0 {
+		changes = append(changes, "lost "+strings.Join(details.LostCommands, ", "))
+	}
+
+	if len(changes) > 0 {
+		message := fmt.Sprintf("%s changed since %s: %s", pkgname, prev.Package, strings.Join(changes, ", "))
+		return types.NewStructuredError(message, details)
+	}
+
+	return nil
+}
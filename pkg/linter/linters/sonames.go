@@ -0,0 +1,263 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linters
+
+import (
+	"bytes"
+	"context"
+	"debug/elf"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+
+	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter/types"
+)
+
+// dependencyName strips the version constraint from a dependency.
+func dependencyName(dep string) string {
+	if i := strings.IndexAny(dep, "=<>~"); i >= 0 {
+		return dep[:i]
+	}
+	return dep
+}
+
+// basenames returns the names of the files and symlinks in fsys.
+func basenames(fsys fs.FS) map[string]bool {
+	names := map[string]bool{}
+	_ = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			names[path.Base(p)] = true
+		}
+		return nil
+	})
+	return names
+}
+
+// provides reports whether one of the provides of a package is the virtual
+// so:soname, with or without a version.
+func provides(pkgProvides []string, soname string) bool {
+	return slices.ContainsFunc(pkgProvides, func(p string) bool {
+		return dependencyName(p) == "so:"+soname
+	})
+}
+
+// sonameResolver decides whether the sonames needed by a package are
+// satisfied, caching what the packages contain and the repositories provide.
+type sonameResolver struct {
+	fsys     fs.FS
+	deps     Dependencies
+	siblings map[string]fs.FS
+	files    map[string]map[string]bool
+	resolved map[string][]*apk.RepositoryPackage
+}
+
+// hasFile reports whether the package, or its sibling named pkgname,
+// contains a file named base.
+func (r *sonameResolver) hasFile(pkgname, base string) bool {
+	names, ok := r.files[pkgname]
+	if !ok {
+		fsys := r.fsys
+		if pkgname != "" {
+			fsys = r.siblings[pkgname]
+		}
+		names = basenames(fsys)
+		r.files[pkgname] = names
+	}
+	return names[base]
+}
+
+func (r *sonameResolver) resolve(dep string) []*apk.RepositoryPackage {
+	if pkgs, ok := r.resolved[dep]; ok {
+		return pkgs
+	}
+	pkgs, err := r.deps.Resolver.ResolvePackage(dep, map[*apk.RepositoryPackage]string{})
+	if err != nil {
+		pkgs = nil
+	}
+	r.resolved[dep] = pkgs
+	return pkgs
+}
+
+// satisfied reports whether soname is vendored in the package, or provided
+// by one of its runtime dependencies.
+func (r *sonameResolver) satisfied(soname string) bool {
+	if provides(r.deps.Provides, soname) || r.hasFile("", soname) {
+		return true
+	}
+
+	for _, dep := range r.deps.Runtime {
+		name := dependencyName(dep)
+
+		// Dependencies on the other packages of this build can't be
+		// resolved from the repositories yet, so look at what they contain.
+		if _, ok := r.siblings[name]; ok {
+			if r.hasFile(name, soname) {
+				return true
+			}
+			continue
+		}
+		if name == "so:"+soname {
+			for sibling := range r.siblings {
+				if r.hasFile(sibling, soname) {
+					return true
+				}
+			}
+		}
+
+		for _, pkg := range r.resolve(name) {
+			if name == "so:"+soname || provides(pkg.Provides, soname) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// neededSonames returns the DT_NEEDED entries of the ELF file at path, if it
+// is one.
+func neededSonames(fsys fs.FS, path string) ([]string, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, nil
+	}
+	defer f.Close()
+
+	readerAt, ok := f.(io.ReaderAt)
+	if !ok {
+		return nil, nil
+	}
+
+	hdr := make([]byte, len(ElfMagic))
+	if _, err := readerAt.ReadAt(hdr, 0); err != nil {
+		return nil, nil
+	}
+
+	if !bytes.Equal(ElfMagic, hdr) {
+		return nil, nil
+	}
+
+	elfFile, err := elf.NewFile(readerAt)
+	if err != nil {
+		return nil, nil
+	}
+	defer elfFile.Close()
+
+	return elfFile.ImportedLibraries()
+}
+
+func SonameLinter(ctx context.Context, _ *config.Configuration, pkgname string, fsys fs.FS) error {
+
+	deps, ok := dependenciesFromContext(ctx)
+	if !ok || deps.Resolver == nil {
+		return fmt.Errorf("%w: the dependencies of %s are not resolvable", ErrNotChecked, pkgname)
+	}
+
+	needed := map[string][]string{}
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if IsIgnoredPath(path) {
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if info.Size() < int64(len(ElfMagic)) {
+			return nil
+		}
+
+		ext := filepath.Ext(path)
+		mode := info.Mode()
+		if mode&0o111 == 0 && !IsObjectFileRegex.MatchString(ext) {
+			return nil
+		}
+
+		sonames, err := neededSonames(fsys, path)
+		if err != nil {
+			return fmt.Errorf("reading DT_NEEDED of %s: %w", path, err)
+		}
+		for _, soname := range sonames {
+			needed[soname] = append(needed[soname], path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r := &sonameResolver{
+		fsys:     fsys,
+		deps:     deps,
+		siblings: siblingsFromContext(ctx),
+		files:    map[string]map[string]bool{},
+		resolved: map[string][]*apk.RepositoryPackage{},
+	}
+
+	var unresolved []types.UnresolvedSoname
+	for _, soname := range slices.Sorted(maps.Keys(needed)) {
+		if r.satisfied(soname) {
+			continue
+		}
+		unresolved = append(unresolved, types.UnresolvedSoname{
+			Soname:   soname,
+			Binaries: needed[soname],
+		})
+	}
+
+	if len(unresolved) > 0 {
+		details := &types.UnresolvedSonameDetails{
+			Sonames: unresolved,
+		}
+
+		sonames := make([]string, 0, len(unresolved))
+		for _, u := range unresolved {
+			sonames = append(sonames, u.Soname)
+		}
+
+		libraryWord := "library"
+		if len(unresolved) > 1 {
+			libraryWord = "libraries"
+		}
+		message := fmt.Sprintf("%s needs %d shared %s which no dependency provides (%s)", pkgname, len(unresolved), libraryWord, strings.Join(sonames, ", "))
+		return types.NewStructuredError(message, details)
+	}
+
+	return nil
+}
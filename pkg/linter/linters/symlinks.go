@@ -0,0 +1,213 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linters
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	apkofs "chainguard.dev/apko/pkg/apk/fs"
+
+	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter/types"
+)
+
+// buildWorkspace is where packages are built, which doesn't exist once they
+// are installed.
+const buildWorkspace = "/home/build"
+
+// maxSymlinkHops is how many symlinks are followed resolving a path before
+// it is considered a cycle, like ELOOP.
+const maxSymlinkHops = 40
+
+const (
+	SymlinkWorkspace = "workspace"
+	SymlinkEscaping  = "escaping"
+	SymlinkDangling  = "dangling"
+	SymlinkCycle     = "cycle"
+)
+
+// splitPath returns the components of a path relative to the root.
+func splitPath(p string) []string {
+	p = strings.Trim(path.Clean("/"+p), "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// escapesRoot reports whether the relative target of the link at p goes
+// above the root of the package.
+func escapesRoot(p, target string) bool {
+	depth := len(splitPath(path.Dir(p)))
+	for _, c := range strings.Split(target, "/") {
+		switch c {
+		case "", ".":
+		case "..":
+			depth--
+			if depth < 0 {
+				return true
+			}
+		default:
+			depth++
+		}
+	}
+	return false
+}
+
+// linkResolver resolves paths across the package and its siblings, which are
+// installed alongside each other.
+type linkResolver struct {
+	fsyss []fs.FS
+}
+
+// readlink returns the target of p if it is a symlink in any of the
+// filesystems.
+func (r *linkResolver) readlink(p string) (string, bool) {
+	for _, fsys := range r.fsyss {
+		rl, ok := fsys.(apkofs.ReadLinkFS)
+		if !ok {
+			continue
+		}
+		if target, err := rl.Readlink(p); err == nil {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+func (r *linkResolver) exists(p string) bool {
+	for _, fsys := range r.fsyss {
+		if _, err := fs.Stat(fsys, p); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve follows the symlinks in each component of p, reporting whether it
+// resolves to a file and whether following it loops.
+func (r *linkResolver) resolve(p string) (exists, loop bool) {
+	components := splitPath(p)
+	cur := ""
+	hops := 0
+
+	for i := 0; i < len(components); i++ {
+		next := path.Join(cur, components[i])
+
+		if target, ok := r.readlink(next); ok {
+			hops++
+			if hops > maxSymlinkHops {
+				return false, true
+			}
+
+			base := target
+			if !path.IsAbs(target) {
+				base = path.Join("/", cur, target)
+			}
+			components = append(splitPath(base), components[i+1:]...)
+			cur = ""
+			i = -1
+			continue
+		}
+
+		if !r.exists(next) {
+			return false, false
+		}
+		cur = next
+	}
+
+	return true, false
+}
+
+func SymlinksLinter(ctx context.Context, _ *config.Configuration, pkgname string, fsys fs.FS) error {
+	rl, ok := fsys.(apkofs.ReadLinkFS)
+	if !ok {
+		return nil
+	}
+
+	siblings := siblingsFromContext(ctx)
+	r := &linkResolver{fsyss: []fs.FS{fsys}}
+	for _, name := range slices.Sorted(maps.Keys(siblings)) {
+		r.fsyss = append(r.fsyss, siblings[name])
+	}
+
+	var links []types.SymlinkInfo
+
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if IsIgnoredPath(p) {
+			return nil
+		}
+
+		if d.Type()&fs.ModeSymlink == 0 {
+			return nil
+		}
+
+		target, err := rl.Readlink(p)
+		if err != nil {
+			return fmt.Errorf("reading symlink %s: %w", p, err)
+		}
+
+		problem := ""
+		if target == buildWorkspace || strings.HasPrefix(target, buildWorkspace+"/") {
+			problem = SymlinkWorkspace
+		} else if !path.IsAbs(target) && escapesRoot(p, target) {
+			problem = SymlinkEscaping
+		} else if exists, loop := r.resolve(p); loop {
+			problem = SymlinkCycle
+		} else if !exists {
+			problem = SymlinkDangling
+		}
+
+		if problem != "" {
+			links = append(links, types.SymlinkInfo{
+				Path:    p,
+				Target:  target,
+				Problem: problem,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(links) > 0 {
+		details := &types.SymlinkDetails{
+			Symlinks: links,
+		}
+
+		linkWord := "symlink"
+		if len(links) > 1 {
+			linkWord = "symlinks"
+		}
+		message := fmt.Sprintf("%s contains %d broken %s", pkgname, len(links), linkWord)
+		return types.NewStructuredError(message, details)
+	}
+
+	return nil
+}
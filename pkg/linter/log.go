@@ -19,7 +19,9 @@ import (
 	"strings"
 
 	"github.com/chainguard-dev/clog"
+	"github.com/dustin/go-humanize"
 
+	"chainguard.dev/melange/pkg/linter/linters"
 	"chainguard.dev/melange/pkg/linter/types"
 )
 
@@ -58,6 +60,53 @@ func logStructuredDetails(log *clog.Logger, details any) {
 			}
 			log.Warnf("    - %s (mode: %s)%s", file.Path, file.Mode, perms)
 		}
+	case *types.HardeningDetails:
+		for _, bin := range d.Binaries {
+			log.Warnf("    - %s (missing: %s)", bin.Path, strings.Join(bin.Missing, ", "))
+		}
+	case *types.UnresolvedSonameDetails:
+		for _, so := range d.Sonames {
+			log.Warnf("    - %s (needed by: %s)", so.Soname, strings.Join(so.Binaries, ", "))
+		}
+	case *types.SymlinkDetails:
+		for _, link := range d.Symlinks {
+			log.Warnf("    - %s -> %s (%s)", link.Path, link.Target, link.Problem)
+		}
+	case *types.LeakDetails:
+		for _, leak := range d.Leaks {
+			what := leak.Kind
+			if leak.Match != "" {
+				what = fmt.Sprintf("%s %s", leak.Kind, leak.Match)
+			}
+			log.Warnf("    - %s: %s at offset %d (%d occurrences)", leak.Path, what, leak.Offset, leak.Count)
+		}
+	case *types.ABIDetails:
+		for _, lib := range d.Libraries {
+			switch lib.Change {
+			case linters.ABISonameBump:
+				log.Warnf("    - %s: %s (%s -> %s)", lib.Path, lib.Change, lib.OldSoname, lib.NewSoname)
+			case linters.ABIRemovedSymbols:
+				log.Warnf("    - %s: %s (%s)", lib.Path, lib.Change, strings.Join(lib.Removed, ", "))
+			default:
+				log.Warnf("    - %s: %s", lib.Path, lib.Change)
+			}
+		}
+	case *types.RegressionDetails:
+		for _, path := range d.Added {
+			log.Warnf("    + %s", path)
+		}
+		for _, path := range d.Removed {
+			log.Warnf("    - %s", path)
+		}
+		for _, bin := range d.Resized {
+			log.Warnf("    ~ %s: %s -> %s", bin.Path, humanize.Bytes(uint64(bin.OldSize)), humanize.Bytes(uint64(bin.NewSize)))
+		}
+		for _, file := range d.Changed {
+			log.Warnf("    ~ %s: %s -> %s", file.Path, file.Old, file.New)
+		}
+		for _, cmd := range d.LostCommands {
+			log.Warnf("    - %s", cmd)
+		}
 	case *types.UnstrippedBinaryDetails:
 		for _, bin := range d.Binaries {
 			log.Warnf("    - %s", bin)
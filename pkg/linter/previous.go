@@ -0,0 +1,258 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	"chainguard.dev/apko/pkg/apk/expandapk"
+	"github.com/chainguard-dev/clog"
+
+	"chainguard.dev/melange/pkg/linter/linters"
+)
+
+// pkgInfo holds the values of a .PKGINFO, some of which repeat.
+type pkgInfo map[string][]string
+
+func parsePkgInfo(data []byte) pkgInfo {
+	info := pkgInfo{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, " = ")
+		if !ok {
+			continue
+		}
+		info[key] = append(info[key], value)
+	}
+	return info
+}
+
+func (info pkgInfo) get(key string) string {
+	if values := info[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// previousPackage is an expanded APK of a previous version.
+type previousPackage struct {
+	exp     *expandapk.APKExpanded
+	name    string
+	version string
+	prev    linters.Previous
+}
+
+// expandPrevious fetches and expands the APK at location.
+func expandPrevious(ctx context.Context, location string) (*previousPackage, error) {
+	r, err := openAPK(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	exp, err := expandapk.ExpandApk(ctx, r, "")
+	if err != nil {
+		return nil, fmt.Errorf("expanding apk %q: %w", location, err)
+	}
+
+	f, err := exp.ControlFS.Open(".PKGINFO")
+	if err != nil {
+		exp.Close()
+		return nil, fmt.Errorf("could not open .PKGINFO file of %q: %w", location, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		exp.Close()
+		return nil, fmt.Errorf("could not read .PKGINFO file of %q: %w", location, err)
+	}
+
+	info := parsePkgInfo(data)
+	name, version := info.get("pkgname"), info.get("pkgver")
+	if name == "" {
+		exp.Close()
+		return nil, fmt.Errorf("pkgname is nonexistent in %q", location)
+	}
+
+	return &previousPackage{
+		exp:     exp,
+		name:    name,
+		version: version,
+		prev: linters.Previous{
+			Package:  fmt.Sprintf("%s-%s", name, version),
+			FS:       exp.TarFS,
+			Provides: info["provides"],
+		},
+	}, nil
+}
+
+// PreviousPackages are the previous versions of packages, which the
+// regressions linter compares them to. It is safe for concurrent use.
+type PreviousPackages struct {
+	mu sync.Mutex
+	// candidates are the locations of the APKs of each package name, by
+	// descending version, which are fetched when needed.
+	candidates map[string][]*apk.Package
+	repo       string
+	expanded   map[string]*previousPackage
+}
+
+// PreviousFromAPKs returns the packages in the APKs at paths, which can be
+// URLs, as the previous versions.
+func PreviousFromAPKs(ctx context.Context, paths []string) (*PreviousPackages, error) {
+	p := &PreviousPackages{expanded: map[string]*previousPackage{}}
+	for _, path := range paths {
+		pkg, err := expandPrevious(ctx, path)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		if _, ok := p.expanded[pkg.name]; ok {
+			pkg.exp.Close()
+			p.Close()
+			return nil, fmt.Errorf("more than one previous version of %s", pkg.name)
+		}
+		p.expanded[pkg.name] = pkg
+	}
+	return p, nil
+}
+
+// PreviousFromRepository returns the packages of the repository for arch as
+// the previous versions, of which the newest lower than the version being
+// linted is used. Their APKs are only fetched when a package of the same name
+// is linted.
+func PreviousFromRepository(ctx context.Context, repo, arch string) (*PreviousPackages, error) {
+	u := fmt.Sprintf("%s/%s/APKINDEX.tar.gz", strings.TrimSuffix(repo, "/"), arch)
+	r, err := openAPK(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("reading index of previous versions: %w", err)
+	}
+	defer r.Close()
+
+	index, err := apk.IndexFromArchive(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", u, err)
+	}
+
+	p := &PreviousPackages{
+		candidates: map[string][]*apk.Package{},
+		repo:       fmt.Sprintf("%s/%s", strings.TrimSuffix(repo, "/"), arch),
+		expanded:   map[string]*previousPackage{},
+	}
+	for _, pkg := range index.Packages {
+		p.candidates[pkg.Name] = append(p.candidates[pkg.Name], pkg)
+	}
+	for _, pkgs := range p.candidates {
+		slices.SortFunc(pkgs, func(a, b *apk.Package) int {
+			return compareVersions(b.Version, a.Version)
+		})
+	}
+
+	return p, nil
+}
+
+// compareVersions compares two APK versions, or, if either doesn't parse,
+// their strings.
+func compareVersions(a, b string) int {
+	va, erra := apk.ParseVersion(a)
+	vb, errb := apk.ParseVersion(b)
+	if erra != nil || errb != nil {
+		return strings.Compare(a, b)
+	}
+	return apk.CompareVersions(va, vb)
+}
+
+// lookup returns the previous version of pkgname: the APK given for it, unless
+// that is version itself, or the newest version of it lower than version in
+// the repository.
+func (p *PreviousPackages) lookup(ctx context.Context, pkgname, version string) (linters.Previous, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pkg, ok := p.expanded[pkgname]; ok {
+		if pkg.version == version {
+			return linters.Previous{}, false, nil
+		}
+		return pkg.prev, true, nil
+	}
+
+	for _, candidate := range p.candidates[pkgname] {
+		if compareVersions(candidate.Version, version) >= 0 {
+			continue
+		}
+		pkg, err := expandPrevious(ctx, fmt.Sprintf("%s/%s", p.repo, candidate.Filename()))
+		if err != nil {
+			return linters.Previous{}, false, err
+		}
+		p.expanded[pkgname] = pkg
+		return pkg.prev, true, nil
+	}
+
+	return linters.Previous{}, false, nil
+}
+
+// Close removes the expanded APKs.
+func (p *PreviousPackages) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var errs []error
+	for name, pkg := range p.expanded {
+		errs = append(errs, pkg.exp.Close())
+		delete(p.expanded, name)
+	}
+	return errors.Join(errs...)
+}
+
+type previousPackagesKey struct{}
+
+// WithPreviousPackages returns a context which lints packages against their
+// previous versions in p.
+func WithPreviousPackages(ctx context.Context, p *PreviousPackages) context.Context {
+	return context.WithValue(ctx, previousPackagesKey{}, p)
+}
+
+// withPrevious returns a context with the previous version of pkgname, if
+// there is one, for the regressions linter.
+func withPrevious(ctx context.Context, pkgname, version string) context.Context {
+	p, _ := ctx.Value(previousPackagesKey{}).(*PreviousPackages)
+	if p == nil {
+		return ctx
+	}
+
+	prev, ok, err := p.lookup(ctx, pkgname, version)
+	if err != nil {
+		clog.FromContext(ctx).Warnf("failed to get the previous version of %s: %v", pkgname, err)
+		return ctx
+	}
+	if !ok {
+		return ctx
+	}
+	return linters.WithPrevious(ctx, prev)
+}
@@ -17,6 +17,7 @@ package linter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -45,9 +46,7 @@ func saveLintResults(ctx context.Context, cfg *config.Configuration, results map
 
 	// Save results for each package
 	for pkgName, pkgResults := range results {
-		// Generate the filename: lint-{packagename}-{version}-r{epoch}.json
-		filename := fmt.Sprintf("lint-%s-%s-r%d.json", pkgName, cfg.Package.Version, cfg.Package.Epoch)
-		filepath := filepath.Join(packageDir, filename)
+		filepath := lintResultsPath(cfg, pkgName, outputDir, arch)
 
 		// Marshal to JSON with indentation for readability
 		jsonData, err := json.MarshalIndent(pkgResults, "", "  ")
@@ -66,3 +65,48 @@ func saveLintResults(ctx context.Context, cfg *config.Configuration, results map
 
 	return nil
 }
+
+// lintResultsPath returns the path of the lint results of pkgName:
+// {outputDir}/{arch}/lint-{packagename}-{version}-r{epoch}.json
+func lintResultsPath(cfg *config.Configuration, pkgName, outputDir, arch string) string {
+	filename := fmt.Sprintf("lint-%s-%s-r%d.json", pkgName, cfg.Package.Version, cfg.Package.Epoch)
+	return filepath.Join(outputDir, arch, filename)
+}
+
+// mergeLintResults adds the findings and reports already saved for the
+// packages of results to them, so that saving results keeps them.
+func mergeLintResults(cfg *config.Configuration, results map[string]*types.PackageLintResults, outputDir, arch string) error {
+	if cfg == nil {
+		return nil
+	}
+
+	for pkgName, pkgResults := range results {
+		data, err := os.ReadFile(lintResultsPath(cfg, pkgName, outputDir, arch))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		saved := &types.PackageLintResults{}
+		if err := json.Unmarshal(data, saved); err != nil {
+			return fmt.Errorf("unmarshaling lint results for %s: %w", pkgName, err)
+		}
+
+		for linterName, findings := range saved.Findings {
+			if _, ok := pkgResults.Findings[linterName]; !ok {
+				pkgResults.Findings[linterName] = findings
+			}
+		}
+		for linterName, r := range saved.Reports {
+			if _, ok := pkgResults.Reports[linterName]; !ok {
+				if pkgResults.Reports == nil {
+					pkgResults.Reports = map[string]*types.LinterFinding{}
+				}
+				pkgResults.Reports[linterName] = r
+			}
+		}
+	}
+
+	return nil
+}
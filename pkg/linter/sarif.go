@@ -0,0 +1,298 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linter
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter/types"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// The subset of SARIF 2.1.0 which lint findings need.
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Help                 *sarifMessage      `json:"help,omitempty"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	RuleIndex  int             `json:"ruleIndex"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations,omitempty"`
+	Properties sarifProperties `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+type sarifProperties struct {
+	Package string `json:"package"`
+	Details any    `json:"details,omitempty"`
+}
+
+// sarifLevel returns the SARIF level of a linter's findings.
+func sarifLevel(b defaultBehavior) string {
+	switch b {
+	case Require:
+		return "error"
+	case Warn:
+		return "warning"
+	default:
+		return "none"
+	}
+}
+
+// sarifURI returns the URI of a file path, relative unless it is absolute.
+func sarifURI(p string) string {
+	u := &url.URL{Path: filepath.ToSlash(p)}
+	if filepath.IsAbs(p) {
+		u.Scheme = "file"
+	}
+	return u.String()
+}
+
+// SARIFReport collects lint findings to write them as a SARIF log. It is
+// safe for concurrent use.
+type SARIFReport struct {
+	mu      sync.Mutex
+	results []sarifResult
+}
+
+// NewSARIFReport returns an empty SARIF report.
+func NewSARIFReport() *SARIFReport {
+	return &SARIFReport{}
+}
+
+// add adds the findings of results to the report. The findings of the
+// linters in require are errors, the others warnings. The reports of linters
+// without findings are notes.
+func (r *SARIFReport) add(cfg *config.Configuration, results map[string]*types.PackageLintResults, require []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for pkgname, pkgResults := range results {
+		var pos config.Position
+		if cfg != nil {
+			pos = cfg.PackagePosition(pkgname)
+		}
+
+		for linterName, findings := range pkgResults.Findings {
+			level := sarifLevel(Warn)
+			if slices.Contains(require, linterName) {
+				level = sarifLevel(Require)
+			}
+
+			for _, finding := range findings {
+				r.results = append(r.results, sarifFindingResult(linterName, level, pos, pkgResults.PackageName, finding))
+			}
+		}
+
+		for linterName, report := range pkgResults.Reports {
+			if len(pkgResults.Findings[linterName]) > 0 {
+				continue
+			}
+			r.results = append(r.results, sarifFindingResult(linterName, "note", pos, pkgResults.PackageName, report))
+		}
+	}
+}
+
+// sarifFindingResult returns the SARIF result of a finding of linterName in
+// the package, whose configuration is at pos.
+func sarifFindingResult(linterName, level string, pos config.Position, pkg string, finding *types.LinterFinding) sarifResult {
+	var locations []sarifLocation
+	// The configuration is where the finding can be addressed.
+	if !pos.IsZero() {
+		locations = append(locations, sarifLocation{
+			PhysicalLocation: &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: sarifURI(pos.File)},
+				Region:           &sarifRegion{StartLine: pos.Line, StartColumn: pos.Column},
+			},
+		})
+	}
+	paths, _ := findingPaths(finding.Details)
+	for _, p := range paths {
+		locations = append(locations, sarifLocation{
+			PhysicalLocation: &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: sarifURI(strings.TrimPrefix(p, "/"))},
+			},
+			LogicalLocations: []sarifLogicalLocation{{Name: pkg, Kind: "package"}},
+		})
+	}
+	if len(locations) == 0 {
+		locations = append(locations, sarifLocation{
+			LogicalLocations: []sarifLogicalLocation{{Name: pkg, Kind: "package"}},
+		})
+	}
+
+	return sarifResult{
+		RuleID:    linterName,
+		Level:     level,
+		Message:   sarifMessage{Text: finding.Message},
+		Locations: locations,
+		Properties: sarifProperties{
+			Package: pkg,
+			Details: finding.Details,
+		},
+	}
+}
+
+// log returns the SARIF log of the report, with a rule for every linter.
+func (r *SARIFReport) log() *sarifLog {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := slices.Sorted(maps.Keys(linterMap))
+	rules := make([]sarifRule, 0, len(names))
+	for _, name := range names {
+		l := linterMap[name]
+		rule := sarifRule{
+			ID:                   name,
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(l.defaultBehavior)},
+		}
+		if l.Explain != "" {
+			rule.Help = &sarifMessage{Text: l.Explain}
+		}
+		rules = append(rules, rule)
+	}
+
+	// Packages are linted concurrently, so order the results.
+	results := slices.Clone(r.results)
+	slices.SortStableFunc(results, func(a, b sarifResult) int {
+		return cmp.Or(
+			strings.Compare(a.Properties.Package, b.Properties.Package),
+			strings.Compare(a.RuleID, b.RuleID),
+		)
+	})
+	for i := range results {
+		results[i].RuleIndex, _ = slices.BinarySearch(names, results[i].RuleID)
+	}
+	if results == nil {
+		results = []sarifResult{}
+	}
+
+	return &sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "melange",
+				InformationURI: "https://github.com/chainguard-dev/melange",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
+
+// Write writes the report to path as a SARIF log.
+func (r *SARIFReport) Write(path string) error {
+	data, err := json.MarshalIndent(r.log(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling SARIF log: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("creating directory for SARIF log: %w", err)
+		}
+	}
+
+	// #nosec G306 - Lint results file should be world-readable
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing SARIF log to %s: %w", path, err)
+	}
+	return nil
+}
+
+type sarifKey struct{}
+
+// WithSARIF returns a context which adds the lint findings to the report r.
+func WithSARIF(ctx context.Context, r *SARIFReport) context.Context {
+	return context.WithValue(ctx, sarifKey{}, r)
+}
+
+func sarifFromContext(ctx context.Context) *SARIFReport {
+	r, _ := ctx.Value(sarifKey{}).(*SARIFReport)
+	return r
+}
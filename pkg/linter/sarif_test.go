@@ -0,0 +1,120 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	apkofs "chainguard.dev/apko/pkg/apk/fs"
+	"github.com/chainguard-dev/clog/slogtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"chainguard.dev/melange/pkg/config"
+	"chainguard.dev/melange/pkg/linter/linters"
+	"chainguard.dev/melange/pkg/linter/types"
+)
+
+func TestSARIFReport(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "usr", "local", "bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "usr", "local", "bin", "foo"), []byte("foo"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "opt"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "opt", "foo"), []byte("foo"), 0o644))
+	fsys := apkofs.DirFS(ctx, dir)
+
+	cfg, err := config.ParseConfiguration(ctx, "foo.yaml", config.WithFS(fstest.MapFS{
+		"foo.yaml": {Data: []byte(`package:
+  name: foo
+  version: 1.0.0
+  epoch: 0
+`)},
+	}))
+	require.NoError(t, err)
+
+	report := NewSARIFReport()
+	sctx := WithSARIF(ctx, report)
+	assert.Error(t, LintBuild(sctx, cfg, "foo", []string{"usrlocal"}, []string{"opt"}, fsys, t.TempDir(), "x86_64"))
+
+	path := filepath.Join(t.TempDir(), "lint.sarif")
+	require.NoError(t, report.Write(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(data, &log))
+
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+
+	// Every linter is a rule, whether it ran or not.
+	rules := run.Tool.Driver.Rules
+	require.Len(t, rules, len(linterMap))
+	for _, rule := range rules {
+		if explain := linterMap[rule.ID].Explain; explain != "" {
+			assert.Equal(t, explain, rule.Help.Text, rule.ID)
+		}
+	}
+
+	require.Len(t, run.Results, 2)
+	levels := map[string]string{}
+	for _, result := range run.Results {
+		assert.Equal(t, result.RuleID, rules[result.RuleIndex].ID)
+		assert.Equal(t, "foo-1.0.0-r0", result.Properties.Package)
+		levels[result.RuleID] = result.Level
+
+		// The package in the configuration, then the paths in the package.
+		require.Len(t, result.Locations, 2)
+		assert.Equal(t, sarifArtifactLocation{URI: "foo.yaml"}, result.Locations[0].PhysicalLocation.ArtifactLocation)
+		assert.Equal(t, &sarifRegion{StartLine: 2, StartColumn: 9}, result.Locations[0].PhysicalLocation.Region)
+		assert.Equal(t, []sarifLogicalLocation{{Name: "foo-1.0.0-r0", Kind: "package"}}, result.Locations[1].LogicalLocations)
+	}
+	assert.Equal(t, map[string]string{"usrlocal": "error", "opt": "warning"}, levels)
+}
+
+func TestSARIFReportNotes(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	libfoo := func(version string) fstest.MapFS {
+		data, err := os.ReadFile(filepath.Join("testdata", "abi", "libfoo.so."+version))
+		require.NoError(t, err)
+		return fstest.MapFS{"usr/lib/libfoo.so." + version: {Data: data, Mode: 0o755}}
+	}
+	pctx := linters.WithPrevious(ctx, linters.Previous{Package: "libfoo-1.0-r0", FS: libfoo("1.0")})
+
+	// Only adding symbols isn't a finding, but it is in the lint results.
+	results := map[string]*types.PackageLintResults{}
+	require.NoError(t, lintPackageFS(pctx, nil, "libfoo", libfoo("1.1"), []string{"abi"}, results, "libfoo-1.1-r0"))
+	require.Contains(t, results, "libfoo")
+	assert.Empty(t, results["libfoo"].Findings)
+	require.Contains(t, results["libfoo"].Reports, "abi")
+	assert.Equal(t, "libfoo changed the ABI of 1 shared library compatibly since libfoo-1.0-r0", results["libfoo"].Reports["abi"].Message)
+
+	report := NewSARIFReport()
+	report.add(nil, results, []string{"abi"})
+	run := report.log().Runs[0]
+	require.Len(t, run.Results, 1)
+	assert.Equal(t, "abi", run.Results[0].RuleID)
+	assert.Equal(t, "note", run.Results[0].Level)
+	assert.IsType(t, &types.ABIDetails{}, run.Results[0].Properties.Details)
+}
@@ -0,0 +1,6 @@
+#!/bin/sh
+set -e
+cc -shared -fPIC -s -Wl,-soname,libfoo.so.1 -o libfoo.so.1.0 libfoo.c
+cc -shared -fPIC -s -Wl,-soname,libfoo.so.1 -DADD_BAZ -o libfoo.so.1.1 libfoo.c
+cc -shared -fPIC -s -Wl,-soname,libfoo.so.1 -DREMOVE_BAR -o libfoo.so.1.2 libfoo.c
+cc -shared -fPIC -s -Wl,-soname,libfoo.so.2 -DREMOVE_BAR -o libfoo.so.2.0 libfoo.c
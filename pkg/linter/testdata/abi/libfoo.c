@@ -0,0 +1,15 @@
+// The shared libraries next to this file are built from it with build.sh,
+// as successive versions of libfoo's ABI.
+
+int foo(void) { return 1; }
+
+#ifndef REMOVE_BAR
+int bar(void) { return 2; }
+#endif
+
+#ifdef ADD_BAZ
+int baz(void) { return 3; }
+#endif
+
+static int hidden(void) { return 4; }
+int call_hidden(void) { return hidden(); }
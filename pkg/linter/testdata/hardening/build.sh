@@ -0,0 +1,3 @@
+#!/bin/sh
+set -e
+cc -no-pie -fno-stack-protector -U_FORTIFY_SOURCE -Wl,-z,norelro -Wl,-z,lazy -s -o hello-nopie hello.c
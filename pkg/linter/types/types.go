@@ -14,6 +14,8 @@
 
 package types
 
+import "strings"
+
 // DuplicateFileInfo represents information about a set of duplicate files
 type DuplicateFileInfo struct {
 	Basename    string   `json:"basename"`
@@ -77,6 +79,61 @@ type UnsupportedArchInfo struct {
 	Arch string `json:"arch"`
 }
 
+// HardeningDetails contains binaries missing required hardening
+type HardeningDetails struct {
+	Binaries []HardeningInfo `json:"binaries"`
+}
+
+// HardeningInfo represents the hardening properties of a binary
+type HardeningInfo struct {
+	Path           string   `json:"path"`
+	PIE            bool     `json:"pie"`
+	RELRO          string   `json:"relro"` // "full", "partial" or "none"
+	BindNow        bool     `json:"bind_now"`
+	NX             bool     `json:"nx"`
+	StackProtector bool     `json:"stack_protector"`
+	Fortify        bool     `json:"fortify"`
+	Missing        []string `json:"missing"` // the required properties it lacks
+}
+
+// UnresolvedSonameDetails contains shared libraries no dependency provides
+type UnresolvedSonameDetails struct {
+	Sonames []UnresolvedSoname `json:"sonames"`
+}
+
+// UnresolvedSoname represents a needed shared library and the binaries
+// needing it
+type UnresolvedSoname struct {
+	Soname   string   `json:"soname"`
+	Binaries []string `json:"binaries"`
+}
+
+// SymlinkDetails contains symlinks which won't work once installed
+type SymlinkDetails struct {
+	Symlinks []SymlinkInfo `json:"symlinks"`
+}
+
+// SymlinkInfo represents a broken symlink
+type SymlinkInfo struct {
+	Path    string `json:"path"`
+	Target  string `json:"target"`
+	Problem string `json:"problem"` // "workspace", "escaping", "dangling" or "cycle"
+}
+
+// LeakDetails contains build paths and secrets found in files
+type LeakDetails struct {
+	Leaks []LeakInfo `json:"leaks"`
+}
+
+// LeakInfo represents a build path or secret found in a file
+type LeakInfo struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"`            // "build-path", "private-key", "aws-access-key" or "netrc-password"
+	Match  string `json:"match,omitempty"` // the build path; secrets are not repeated
+	Offset int64  `json:"offset"`          // byte offset of the first occurrence
+	Count  int    `json:"count"`
+}
+
 // PythonMultipleDetails contains info about multiple Python packages
 type PythonMultipleDetails struct {
 	Count    int      `json:"count"`
@@ -104,6 +161,51 @@ type NonLinuxDetails struct {
 	References []NonLinuxReference `json:"references"`
 }
 
+// ResizedBinary represents a binary whose size changed since the previous
+// version of its package
+type ResizedBinary struct {
+	Path    string `json:"path"`
+	OldSize int64  `json:"old_size"`
+	NewSize int64  `json:"new_size"`
+}
+
+// ChangedFile represents a file whose mode or ownership changed since the
+// previous version of its package
+type ChangedFile struct {
+	Path string `json:"path"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// RegressionDetails contains the changes of a package since its previous
+// version
+type RegressionDetails struct {
+	Previous     string          `json:"previous"`
+	Added        []string        `json:"added,omitempty"`
+	Removed      []string        `json:"removed,omitempty"`
+	Resized      []ResizedBinary `json:"resized,omitempty"`
+	Changed      []ChangedFile   `json:"changed,omitempty"`
+	LostCommands []string        `json:"lost_commands,omitempty"`
+}
+
+// ABIChange represents how the ABI of a shared library changed since the
+// previous version of its package
+type ABIChange struct {
+	Path      string   `json:"path"`
+	OldSoname string   `json:"old_soname,omitempty"`
+	NewSoname string   `json:"new_soname,omitempty"`
+	Change    string   `json:"change"`
+	Added     []string `json:"added,omitempty"`
+	Removed   []string `json:"removed,omitempty"`
+}
+
+// ABIDetails contains the ABI changes of the shared libraries of a package
+// since its previous version
+type ABIDetails struct {
+	Previous  string      `json:"previous"`
+	Libraries []ABIChange `json:"libraries"`
+}
+
 // StructuredError is an error that carries structured details for JSON serialization
 type StructuredError struct {
 	Message string
@@ -123,6 +225,32 @@ func NewStructuredError(message string, details any) error {
 	}
 }
 
+// FindingsError is an error that carries several findings of one linter, like
+// those an external linter reports
+type FindingsError struct {
+	Findings []*LinterFinding
+}
+
+// Error returns the messages of the findings
+func (e *FindingsError) Error() string {
+	messages := make([]string, 0, len(e.Findings))
+	for _, f := range e.Findings {
+		messages = append(messages, f.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// PackageDescription describes the package an external linter lints
+type PackageDescription struct {
+	Name        string   `json:"name"`
+	Origin      string   `json:"origin,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	Epoch       uint64   `json:"epoch"`
+	Description string   `json:"description,omitempty"`
+	Runtime     []string `json:"runtime,omitempty"`
+	Provides    []string `json:"provides,omitempty"`
+}
+
 // LinterFinding represents a single finding from a linter
 type LinterFinding struct {
 	Message string `json:"message"`
@@ -134,4 +262,7 @@ type LinterFinding struct {
 type PackageLintResults struct {
 	PackageName string                      `json:"package_name"`
 	Findings    map[string][]*LinterFinding `json:"findings"` // map of linter name -> findings
+	// Reports are what linters found out about the package whether or not
+	// it is a finding, by linter name
+	Reports map[string]*LinterFinding `json:"reports,omitempty"`
 }
@@ -86,6 +86,10 @@ type Package struct {
 	// source locations; Leaving this empty will result in NOASSERTION being
 	// used as its value.
 	DownloadLocation string
+
+	// Free-form information about where the package came from, such as the
+	// file of the APK package it was found in, if any.
+	SourceInfo string
 }
 
 // ToSPDX returns the Package converted to its SPDX representation.
@@ -95,7 +99,7 @@ func (p Package) ToSPDX(ctx context.Context) spdx.Package {
 	if p.LicenseDeclared == "" {
 		log.Warnf("%s: no license specified, defaulting to %s", p.ID(), spdx.NOASSERTION)
 		p.LicenseDeclared = spdx.NOASSERTION
-	} else {
+	} else if p.LicenseDeclared != spdx.NOASSERTION {
 		valid, bad := spdxexp.ValidateLicenses([]string{p.LicenseDeclared})
 		if !valid {
 			log.Warnf("invalid license: %s", strings.Join(bad, ", "))
@@ -114,6 +118,7 @@ func (p Package) ToSPDX(ctx context.Context) spdx.Package {
 		LicenseConcluded: spdx.NOASSERTION,
 		LicenseDeclared:  p.LicenseDeclared,
 		DownloadLocation: p.DownloadLocation,
+		SourceInfo:       p.SourceInfo,
 		CopyrightText:    p.Copyright,
 		Checksums:        p.getChecksums(),
 		ExternalRefs:     p.getExternalRefs(),
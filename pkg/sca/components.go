@@ -0,0 +1,103 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"context"
+	"debug/elf"
+	"io"
+	"io/fs"
+
+	"github.com/chainguard-dev/clog"
+)
+
+// Component is third-party software built into a file of a package, such as a
+// module compiled into a Go binary. Components aren't packages of their own,
+// but belong in the SBOM of the package they were found in.
+type Component struct {
+	// Type is the package URL type of the component, such as "golang".
+	Type string
+	// Name and Version identify the component in its ecosystem.
+	Name    string
+	Version string
+	// Sum is the checksum its ecosystem identifies its source with, if any.
+	Sum string
+	// Path is the file of the package the component was found in.
+	Path string
+	// Main is whether the file was built from the component, rather than
+	// depending on it.
+	Main bool
+	// Notes flag anything unusual about how the component was built, such as
+	// a replaced module.
+	Notes []string
+}
+
+// A componentFinder returns the components built into the ELF file at path.
+type componentFinder func(ctx context.Context, path string, f io.ReaderAt, ef *elf.File) ([]Component, error)
+
+var componentFinders = []componentFinder{
+	findGoComponents,
+	findRustComponents,
+}
+
+// Components returns the third-party components built into the ELF files in
+// fsys.
+func Components(ctx context.Context, fsys SCAFS) ([]Component, error) {
+	log := clog.FromContext(ctx)
+	log.Infof("scanning for built-in components...")
+
+	var components []Component
+	if err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		f, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		ra, ok := f.(io.ReaderAt)
+		if !ok {
+			return nil
+		}
+
+		ef, err := elf.NewFile(ra)
+		if err != nil {
+			return nil
+		}
+		defer ef.Close()
+
+		for _, find := range componentFinders {
+			found, err := find(ctx, path, ra, ef)
+			if err != nil {
+				log.Warnf("Unable to read components of %s: %v", path, err)
+				continue
+			}
+			components = append(components, found...)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return components, nil
+}
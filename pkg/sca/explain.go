@@ -0,0 +1,122 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"context"
+	"slices"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+// The kinds of generated dependencies, named after the fields of
+// config.Dependencies.
+const (
+	KindRuntime  = "runtime"
+	KindProvides = "provides"
+	KindVendored = "vendored"
+)
+
+// Explanation records why the SCA engine generated a dependency.
+type Explanation struct {
+	// Kind is KindRuntime, KindProvides or KindVendored.
+	Kind string `json:"kind"`
+	// Entry is the generated dependency, such as so:libssl.so.3.
+	Entry string `json:"entry"`
+	// Generator is the name of the generator that found it.
+	Generator string `json:"generator"`
+	// Path is the file of the package it was found in, if any.
+	Path string `json:"path,omitempty"`
+	// Reason is what was found, such as "DT_NEEDED libssl.so.3".
+	Reason string `json:"reason"`
+}
+
+// Explanations are the explanations of a set of generated dependencies.
+type Explanations []Explanation
+
+// For returns the explanations of the dependencies in deps, leaving out
+// those of dependencies that were dropped since they were generated.
+func (es Explanations) For(deps config.Dependencies) Explanations {
+	lists := map[string][]string{
+		KindRuntime:  deps.Runtime,
+		KindProvides: deps.Provides,
+		KindVendored: deps.Vendored,
+	}
+
+	var out Explanations
+	for _, e := range es {
+		if slices.Contains(lists[e.Kind], e.Entry) && !slices.Contains(out, e) {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+// explainer collects explanations while the generators run.
+type explainer struct {
+	generator    string
+	explanations Explanations
+}
+
+type explainerKey struct{}
+
+func explainerFromContext(ctx context.Context) *explainer {
+	e, _ := ctx.Value(explainerKey{}).(*explainer)
+	return e
+}
+
+func explain(ctx context.Context, kind, entry, path, reason string) {
+	e := explainerFromContext(ctx)
+	if e == nil {
+		return
+	}
+	e.explanations = append(e.explanations, Explanation{
+		Kind:      kind,
+		Entry:     entry,
+		Generator: e.generator,
+		Path:      path,
+		Reason:    reason,
+	})
+}
+
+// addRuntime adds a runtime dependency found in the file at path.
+func addRuntime(ctx context.Context, generated *config.Dependencies, dep, path, reason string) {
+	generated.Runtime = append(generated.Runtime, dep)
+	explain(ctx, KindRuntime, dep, path, reason)
+}
+
+// addProvides adds a provided name found in the file at path.
+func addProvides(ctx context.Context, generated *config.Dependencies, dep, path, reason string) {
+	generated.Provides = append(generated.Provides, dep)
+	explain(ctx, KindProvides, dep, path, reason)
+}
+
+// addVendored adds a vendored name found in the file at path.
+func addVendored(ctx context.Context, generated *config.Dependencies, dep, path, reason string) {
+	generated.Vendored = append(generated.Vendored, dep)
+	explain(ctx, KindVendored, dep, path, reason)
+}
+
+// Explain runs the SCA analyzers like Analyze, and returns why each of the
+// generated dependencies was generated.
+func Explain(ctx context.Context, hdl SCAHandle, generated *config.Dependencies) (Explanations, error) {
+	e := &explainer{}
+	if err := Analyze(context.WithValue(ctx, explainerKey{}, e), hdl, generated); err != nil {
+		return nil, err
+	}
+
+	return e.explanations.For(*generated), nil
+}
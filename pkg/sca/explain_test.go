@@ -0,0 +1,96 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/chainguard-dev/clog/slogtest"
+	"github.com/google/go-cmp/cmp"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+func TestExplain(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	hdl := &memHandle{
+		name:    "foo",
+		version: "1.0-r0",
+		files: fstest.MapFS{
+			"usr/share/perl5/vendor_perl/Foo.pm": {Data: []byte("package Foo;\n1;\n")},
+			"usr/share/app/app.jar": {Data: makeJar(t, map[string][]byte{
+				"META-INF/maven/org.example/app/pom.properties": []byte("groupId=org.example\nartifactId=app\n"),
+				"org/example/App.class":                         classFile(61),
+			})},
+		},
+		relatives: map[string]fstest.MapFS{
+			"openjdk-17": {
+				"usr/lib/jvm/java-17-openjdk/release": {Data: []byte("JAVA_VERSION=\"17.0.15\"\n")},
+			},
+		},
+	}
+
+	got := config.Dependencies{}
+	explanations, err := Explain(ctx, hdl, &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Explanations{{
+		Kind:      KindProvides,
+		Entry:     "perl(Foo)=1.0-r0",
+		Generator: "perl",
+		Path:      "usr/share/perl5/vendor_perl/Foo.pm",
+		Reason:    "perl module Foo",
+	}, {
+		Kind:      KindRuntime,
+		Entry:     "perl",
+		Generator: "perl",
+		Path:      "usr/share/perl5/vendor_perl/Foo.pm",
+		Reason:    "perl module",
+	}, {
+		Kind:      KindVendored,
+		Entry:     "maven:org.example:app=1.0-r0",
+		Generator: "java",
+		Path:      "usr/share/app/app.jar",
+		Reason:    "pom.properties outside of usr/share/java/",
+	}, {
+		Kind:      KindRuntime,
+		Entry:     "java-runtime-17",
+		Generator: "java",
+		Path:      "usr/share/app/app.jar",
+		Reason:    "class files for java 17",
+	}}
+	if diff := cmp.Diff(want, explanations); diff != "" {
+		t.Errorf("Explain(): (-want, +got):\n%s", diff)
+	}
+
+	// Explaining must not change what is generated.
+	analyzed := config.Dependencies{}
+	if err := Analyze(ctx, hdl, &analyzed); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(analyzed, got); diff != "" {
+		t.Errorf("Explain() dependencies: (-Analyze, +Explain):\n%s", diff)
+	}
+
+	// Dropped dependencies are not explained.
+	kept := explanations.For(config.Dependencies{Runtime: []string{"perl"}})
+	if diff := cmp.Diff(want[1:2], kept); diff != "" {
+		t.Errorf("For(): (-want, +got):\n%s", diff)
+	}
+}
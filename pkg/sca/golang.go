@@ -0,0 +1,97 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"context"
+	"debug/buildinfo"
+	"debug/elf"
+	"fmt"
+	"io"
+	"runtime/debug"
+
+	"github.com/chainguard-dev/clog"
+)
+
+// goDevelVersion is the version of main modules built outside of a module
+// download, such as from a git checkout without version control information.
+const goDevelVersion = "(devel)"
+
+// findGoComponents returns the main module and dependency modules of a Go
+// binary, according to the build info embedded in it.
+func findGoComponents(ctx context.Context, path string, f io.ReaderAt, _ *elf.File) ([]Component, error) {
+	log := clog.FromContext(ctx)
+
+	bi, err := buildinfo.Read(f)
+	if err != nil {
+		// Not a Go binary.
+		return nil, nil
+	}
+
+	log.Infof("  found go binary %s built from %s", path, bi.Main.Path)
+
+	var components []Component
+	if bi.Main.Path != "" {
+		main := goComponent(path, &bi.Main, true)
+		if bi.Main.Version == "" || bi.Main.Version == goDevelVersion {
+			log.Warnf("%s: go binary built from a %s version of %s", path, goDevelVersion, bi.Main.Path)
+			main.Notes = append(main.Notes, "built from a "+goDevelVersion+" version")
+		}
+		components = append(components, main)
+	}
+
+	for _, dep := range bi.Deps {
+		c := goComponent(path, dep, false)
+		if dep.Replace != nil {
+			log.Warnf("%s: go binary built with %s replaced by %s", path, dep.Path, goModuleString(dep.Replace))
+		}
+		components = append(components, c)
+	}
+
+	return components, nil
+}
+
+// goComponent returns the component for a module of the Go binary at path.
+func goComponent(path string, m *debug.Module, main bool) Component {
+	c := Component{
+		Type:    "golang",
+		Name:    m.Path,
+		Version: m.Version,
+		Sum:     m.Sum,
+		Path:    path,
+		Main:    main,
+	}
+
+	if m.Replace != nil {
+		c.Notes = append(c.Notes, fmt.Sprintf("%s replaced by %s", goModuleString(m), goModuleString(m.Replace)))
+
+		// What was built is the replacement, unless it is a local directory,
+		// which has no version to identify it by.
+		if m.Replace.Version != "" {
+			c.Name = m.Replace.Path
+			c.Version = m.Replace.Version
+			c.Sum = m.Replace.Sum
+		}
+	}
+
+	return c
+}
+
+func goModuleString(m *debug.Module) string {
+	if m.Version == "" {
+		return m.Path
+	}
+	return m.Path + " " + m.Version
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"os"
+	"runtime/debug"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/chainguard-dev/clog/slogtest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGoComponents(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	// The test binary is a Go binary with build info.
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := memFS{fstest.MapFS{
+		"usr/bin/foo":       {Data: data},
+		"usr/share/foo.txt": {Data: []byte("not a binary")},
+	}}
+
+	components, err := Components(ctx, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(components) == 0 || !components[0].Main || components[0].Type != "golang" {
+		t.Fatalf("Components(): want the main module first, got %v", components)
+	}
+	if components[0].Path != "usr/bin/foo" {
+		t.Errorf("Components(): want path usr/bin/foo, got %q", components[0].Path)
+	}
+
+	if !slices.ContainsFunc(components, func(c Component) bool {
+		return c.Name == "github.com/google/go-cmp" && c.Version != "" && c.Sum != "" && !c.Main
+	}) {
+		t.Errorf("Components(): want github.com/google/go-cmp dependency, got %v", components)
+	}
+}
+
+func TestGoComponent(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		mod  debug.Module
+		want Component
+	}{{
+		name: "module",
+		mod:  debug.Module{Path: "example.com/foo", Version: "v1.2.3", Sum: "h1:foo="},
+		want: Component{Type: "golang", Name: "example.com/foo", Version: "v1.2.3", Sum: "h1:foo=", Path: "usr/bin/foo"},
+	}, {
+		name: "replaced by module",
+		mod: debug.Module{Path: "example.com/foo", Version: "v1.2.3", Replace: &debug.Module{
+			Path: "example.com/fork", Version: "v1.2.4", Sum: "h1:fork=",
+		}},
+		want: Component{
+			Type: "golang", Name: "example.com/fork", Version: "v1.2.4", Sum: "h1:fork=", Path: "usr/bin/foo",
+			Notes: []string{"example.com/foo v1.2.3 replaced by example.com/fork v1.2.4"},
+		},
+	}, {
+		name: "replaced by directory",
+		mod:  debug.Module{Path: "example.com/foo", Version: "v1.2.3", Replace: &debug.Module{Path: "../foo"}},
+		want: Component{
+			Type: "golang", Name: "example.com/foo", Version: "v1.2.3", Path: "usr/bin/foo",
+			Notes: []string{"example.com/foo v1.2.3 replaced by ../foo"},
+		},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			got := goComponent("usr/bin/foo", &c.mod, false)
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("goComponent(): (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
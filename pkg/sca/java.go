@@ -0,0 +1,379 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+// The oldest Java runtime we generate dependencies on, any older class file
+// runs on it.
+const minJavaRuntime = 8
+
+// The largest jar nested in another jar we read into memory to inspect.
+const maxNestedJarSize = 256 << 20
+
+// javaLibDir is where Java libraries meant to be shared are installed.
+const javaLibDir = "usr/share/java/"
+
+// jarInfo is what we learn from inspecting a jar.
+type jarInfo struct {
+	// The Java runtime version its classes need, or 0 if unknown.
+	javaVersion int
+	// The groupId:artifactId Maven coordinates of the jar, and of the jars
+	// nested in it.
+	maven, nestedMaven []string
+}
+
+// javaVersionForClass returns the Java runtime version that can load class
+// files of the given major version.
+func javaVersionForClass(major uint16) int {
+	// Class file versions start at 45 for Java 1.0 and 1.1.
+	if major < 45 {
+		return 0
+	}
+	return max(int(major)-44, minJavaRuntime)
+}
+
+var javaSpecRegexp = regexp.MustCompile(`^(?:1\.)?(\d+)`)
+
+// javaVersionForSpec returns the Java runtime version of a specification
+// version such as 1.8 or 17.
+func javaVersionForSpec(spec string) int {
+	m := javaSpecRegexp.FindStringSubmatch(strings.TrimSpace(spec))
+	if m == nil {
+		return 0
+	}
+	v, _ := strconv.Atoi(m[1])
+	return max(v, minJavaRuntime)
+}
+
+// readManifest parses the main section of a jar manifest.
+func readManifest(r io.Reader) map[string]string {
+	attrs := map[string]string{}
+
+	var last string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			// The main section ends at the first blank line.
+			break
+		}
+
+		// Long values are continued on lines starting with a space.
+		if strings.HasPrefix(line, " ") {
+			if last != "" {
+				attrs[last] += line[1:]
+			}
+			continue
+		}
+
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		last = strings.TrimSpace(k)
+		attrs[last] = strings.TrimSpace(v)
+	}
+
+	return attrs
+}
+
+// readProperties parses a Java properties file, such as pom.properties.
+func readProperties(r io.Reader) map[string]string {
+	props := map[string]string{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			k, v, _ = strings.Cut(line, ":")
+		}
+		props[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+
+	return props
+}
+
+// inspectJar returns what the classes, manifest and Maven metadata of the jar
+// read from r tell us. Jars nested in it are inspected too, but not deeper.
+func inspectJar(r io.ReaderAt, size int64, nested bool) (jarInfo, error) {
+	var info jarInfo
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return info, err
+	}
+
+	var buildJdkSpec string
+	for _, f := range zr.File {
+		name := f.Name
+
+		switch {
+		case name == "META-INF/MANIFEST.MF":
+			rc, err := f.Open()
+			if err != nil {
+				return info, err
+			}
+			buildJdkSpec = readManifest(rc)["Build-Jdk-Spec"]
+			rc.Close()
+
+		// Classes for newer runtimes in multi-release jars are optional.
+		case strings.HasPrefix(name, "META-INF/versions/"):
+			continue
+
+		case strings.HasSuffix(name, ".class"):
+			rc, err := f.Open()
+			if err != nil {
+				return info, err
+			}
+			var hdr [8]byte
+			_, err = io.ReadFull(rc, hdr[:])
+			rc.Close()
+			if err != nil || binary.BigEndian.Uint32(hdr[:4]) != 0xcafebabe {
+				continue
+			}
+			info.javaVersion = max(info.javaVersion, javaVersionForClass(binary.BigEndian.Uint16(hdr[6:])))
+
+		case strings.HasPrefix(name, "META-INF/maven/") && path.Base(name) == "pom.properties":
+			rc, err := f.Open()
+			if err != nil {
+				return info, err
+			}
+			props := readProperties(rc)
+			rc.Close()
+			if props["groupId"] != "" && props["artifactId"] != "" {
+				info.maven = append(info.maven, props["groupId"]+":"+props["artifactId"])
+			}
+
+		case strings.HasSuffix(name, ".jar") && !nested && f.UncompressedSize64 <= maxNestedJarSize:
+			rc, err := f.Open()
+			if err != nil {
+				return info, err
+			}
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return info, err
+			}
+
+			inner, err := inspectJar(bytes.NewReader(data), int64(len(data)), true)
+			if err != nil {
+				// Not every file named .jar is one.
+				continue
+			}
+			info.javaVersion = max(info.javaVersion, inner.javaVersion)
+			info.nestedMaven = append(info.nestedMaven, inner.maven...)
+		}
+	}
+
+	// Resource-only jars have no classes to tell which runtime they are for.
+	if info.javaVersion == 0 {
+		info.javaVersion = javaVersionForSpec(buildJdkSpec)
+	}
+
+	return info, nil
+}
+
+var javaReleaseRegexp = regexp.MustCompile(`(?m)^JAVA_VERSION="?([^"\n]+)"?`)
+
+// javaRuntimeVersion returns the version of the Java runtime shipped in fsys,
+// if any, and the release file it was read from.
+func javaRuntimeVersion(fsys fs.FS) (int, string, error) {
+	releases, err := fs.Glob(fsys, "usr/lib/jvm/*/release")
+	if err != nil {
+		return 0, "", err
+	}
+
+	version, file := 0, ""
+	for _, release := range releases {
+		data, err := fs.ReadFile(fsys, release)
+		if err != nil {
+			return 0, "", err
+		}
+		m := javaReleaseRegexp.FindSubmatch(data)
+		if m == nil {
+			continue
+		}
+		if v := javaVersionForSpec(string(m[1])); v > version {
+			version, file = v, release
+		}
+	}
+
+	return version, file, nil
+}
+
+// generateJavaDeps generates a java-runtime-N dependency for packages which
+// ship jars, java-runtime-N provides for packages which ship a Java runtime,
+// and maven:groupId:artifactId provides for the Maven artifacts in jars.
+func generateJavaDeps(ctx context.Context, hdl SCAHandle, generated *config.Dependencies, extraLibDirs []string) error {
+	log := clog.FromContext(ctx)
+	log.Infof("scanning for java artifacts...")
+
+	fsys, err := hdl.Filesystem()
+	if err != nil {
+		return err
+	}
+
+	runtimeVersion, runtimeRelease, err := javaRuntimeVersion(fsys)
+	if err != nil {
+		return err
+	}
+	if runtimeVersion > 0 {
+		log.Infof("  found java %d runtime", runtimeVersion)
+		for v := minJavaRuntime; v <= runtimeVersion; v++ {
+			addProvides(ctx, generated, fmt.Sprintf("java-runtime-%d=%s", v, hdl.Version()), runtimeRelease, fmt.Sprintf("JAVA_VERSION %d", runtimeVersion))
+		}
+	}
+
+	// The newest Java runtime needed by the jars, and the first jar that
+	// needs it.
+	javaVersion := 0
+	var javaJar string
+	if err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() || path.Ext(p) != ".jar" {
+			return nil
+		}
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		f, err := fsys.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		ra, ok := f.(io.ReaderAt)
+		if !ok {
+			return nil
+		}
+
+		info, err := inspectJar(ra, fi.Size(), false)
+		if err != nil {
+			log.Warnf("Unable to inspect jar (%s): %v", p, err)
+			return nil
+		}
+
+		if info.javaVersion > 0 {
+			log.Infof("  found jar %s for java %d", p, info.javaVersion)
+			if info.javaVersion > javaVersion {
+				javaVersion, javaJar = info.javaVersion, p
+			}
+		}
+
+		// Only jars installed for other packages to use are provided, the
+		// ones bundled with applications are vendored.
+		for _, coords := range info.maven {
+			if strings.HasPrefix(p, javaLibDir) {
+				log.Infof("  found maven artifact %s for %s", coords, p)
+				addProvides(ctx, generated, fmt.Sprintf("maven:%s=%s", coords, hdl.Version()), p, "pom.properties in "+javaLibDir)
+			} else {
+				log.Infof("  found vendored maven artifact %s for %s", coords, p)
+				addVendored(ctx, generated, fmt.Sprintf("maven:%s=%s", coords, hdl.Version()), p, "pom.properties outside of "+javaLibDir)
+			}
+		}
+		for _, coords := range info.nestedMaven {
+			log.Infof("  found vendored maven artifact %s in %s", coords, p)
+			addVendored(ctx, generated, fmt.Sprintf("maven:%s=%s", coords, hdl.Version()), p, "pom.properties in nested jar")
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Nothing to do...
+	if javaVersion == 0 || runtimeVersion > 0 {
+		return nil
+	}
+
+	// Do not add a Java dependency if one already exists.
+	for _, dep := range hdl.BaseDependencies().Runtime {
+		if strings.HasPrefix(dep, "openjdk") || strings.HasPrefix(dep, "java-") {
+			log.Warnf("%s: Java dependency %q already specified, consider removing it in favor of SCA-generated dependency", hdl.PackageName(), dep)
+			return nil
+		}
+	}
+
+	// Only runtimes built with the java-runtime-N provides can satisfy the
+	// dependency, so make sure one of them is available.
+	dep := fmt.Sprintf("java-runtime-%d", javaVersion)
+	resolvable, err := javaRuntimeResolvable(hdl, dep, javaVersion)
+	if err != nil {
+		return err
+	}
+	if !resolvable {
+		log.Warnf("%s: nothing provides %s, required by %s, not generating a Java dependency", hdl.PackageName(), dep, javaJar)
+		return nil
+	}
+
+	log.Infof("  found jars, generating java-runtime-%d dependency", javaVersion)
+	addRuntime(ctx, generated, dep, javaJar, fmt.Sprintf("class files for java %d", javaVersion))
+
+	return nil
+}
+
+// javaRuntimeResolvable returns whether dep, the runtime for java version, is
+// provided by one of the other packages being built, or by a repository.
+func javaRuntimeResolvable(hdl SCAHandle, dep string, version int) (bool, error) {
+	for _, pkg := range hdl.RelativeNames() {
+		if pkg == hdl.PackageName() {
+			continue
+		}
+
+		rfs, err := hdl.FilesystemForRelative(pkg)
+		if err != nil {
+			return false, err
+		}
+
+		rversion, _, err := javaRuntimeVersion(rfs)
+		if err != nil {
+			return false, err
+		}
+		if rversion >= version {
+			return true, nil
+		}
+	}
+
+	return isResolvable(hdl.PkgResolver(), dep), nil
+}
@@ -0,0 +1,224 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"chainguard.dev/apko/pkg/apk/apk"
+	"github.com/chainguard-dev/clog/slogtest"
+	"github.com/google/go-cmp/cmp"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+// classFile returns the header of a class file of the given major version.
+func classFile(major byte) []byte {
+	return []byte{0xca, 0xfe, 0xba, 0xbe, 0, 0, 0, major}
+}
+
+func makeJar(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestJavaSca(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	library := makeJar(t, map[string][]byte{
+		"META-INF/MANIFEST.MF":                          []byte("Manifest-Version: 1.0\r\nMulti-Release: true\r\n\r\n"),
+		"META-INF/maven/org.example/foo/pom.properties": []byte("#Generated by Maven\ngroupId=org.example\nartifactId=foo\nversion=1.0\n"),
+		"org/example/Foo.class":                         classFile(55),
+		"META-INF/versions/21/org/example/Foo.class":    classFile(65),
+	})
+
+	app := makeJar(t, map[string][]byte{
+		"META-INF/MANIFEST.MF":                          []byte("Manifest-Version: 1.0\nMain-Class: org.example.App\n"),
+		"META-INF/maven/org.example/app/pom.properties": []byte("groupId=org.example\nartifactId=app\n"),
+		"org/example/App.class":                         classFile(52),
+		"BOOT-INF/lib/bar.jar": makeJar(t, map[string][]byte{
+			"META-INF/maven/org.example/bar/pom.properties": []byte("groupId=org.example\nartifactId=bar\n"),
+			"org/example/Bar.class":                         classFile(61),
+		}),
+	})
+
+	resources := makeJar(t, map[string][]byte{
+		"META-INF/MANIFEST.MF": []byte("Manifest-Version: 1.0\nBuild-Jdk-Spec: 1.8\n"),
+		"logo.png":             nil,
+	})
+
+	java21 := makeJar(t, map[string][]byte{
+		"org/example/New.class": classFile(65),
+	})
+
+	// The repository has a runtime for up to java 17.
+	var provides []string
+	for v := 8; v <= 17; v++ {
+		provides = append(provides, fmt.Sprintf("java-runtime-%d=17.0.15-r0", v))
+	}
+	repo := apk.Repository{URI: "test"}
+	resolver := apk.NewPkgResolver(ctx, []apk.NamedIndex{
+		apk.NewNamedRepositoryWithIndex("", repo.WithIndex(&apk.APKIndex{
+			Packages: []*apk.Package{{
+				Name:     "openjdk-17",
+				Version:  "17.0.15-r0",
+				Provides: provides,
+			}},
+		})),
+	})
+
+	for _, c := range []struct {
+		name      string
+		files     fstest.MapFS
+		relatives map[string]fstest.MapFS
+		deps      config.Dependencies
+		noRepo    bool
+		want      config.Dependencies
+	}{{
+		name: "library",
+		files: fstest.MapFS{
+			"usr/share/java/foo.jar": {Data: library},
+		},
+		want: config.Dependencies{
+			Runtime:  []string{"java-runtime-11"},
+			Provides: []string{"maven:org.example:foo=1.0-r0"},
+		},
+	}, {
+		name: "application",
+		files: fstest.MapFS{
+			"usr/share/app/app.jar":  {Data: app},
+			"usr/share/app/logo.jar": {Data: resources},
+		},
+		want: config.Dependencies{
+			Runtime: []string{"java-runtime-17"},
+			Vendored: []string{
+				"maven:org.example:app=1.0-r0",
+				"maven:org.example:bar=1.0-r0",
+			},
+		},
+	}, {
+		name: "resources only",
+		files: fstest.MapFS{
+			"usr/share/java/logo.jar": {Data: resources},
+		},
+		want: config.Dependencies{
+			Runtime: []string{"java-runtime-8"},
+		},
+	}, {
+		name: "java already specified",
+		files: fstest.MapFS{
+			"usr/share/app/app.jar": {Data: app},
+		},
+		deps: config.Dependencies{Runtime: []string{"openjdk-21-default-jvm"}},
+		want: config.Dependencies{
+			Vendored: []string{
+				"maven:org.example:app=1.0-r0",
+				"maven:org.example:bar=1.0-r0",
+			},
+		},
+	}, {
+		name: "runtime",
+		files: fstest.MapFS{
+			"usr/lib/jvm/java-11-openjdk/release":     {Data: []byte("IMPLEMENTOR=\"Wolfi\"\nJAVA_VERSION=\"11.0.25\"\n")},
+			"usr/lib/jvm/java-11-openjdk/lib/jrt.jar": {Data: library},
+		},
+		want: config.Dependencies{
+			Provides: []string{
+				"java-runtime-10=1.0-r0",
+				"java-runtime-11=1.0-r0",
+				"java-runtime-8=1.0-r0",
+				"java-runtime-9=1.0-r0",
+			},
+			Vendored: []string{"maven:org.example:foo=1.0-r0"},
+		},
+	}, {
+		name: "no provider of the runtime",
+		files: fstest.MapFS{
+			"usr/share/java/new.jar": {Data: java21},
+		},
+		want: config.Dependencies{},
+	}, {
+		name: "no repository",
+		files: fstest.MapFS{
+			"usr/share/java/foo.jar": {Data: library},
+		},
+		noRepo: true,
+		want: config.Dependencies{
+			Provides: []string{"maven:org.example:foo=1.0-r0"},
+		},
+	}, {
+		name: "runtime built alongside",
+		files: fstest.MapFS{
+			"usr/share/java/new.jar": {Data: java21},
+		},
+		relatives: map[string]fstest.MapFS{
+			"openjdk-21": {
+				"usr/lib/jvm/java-21-openjdk/release": {Data: []byte("JAVA_VERSION=\"21.0.7\"\n")},
+			},
+		},
+		want: config.Dependencies{
+			Runtime: []string{"java-runtime-21"},
+		},
+	}, {
+		name: "not a jar",
+		files: fstest.MapFS{
+			"usr/share/java/broken.jar": {Data: []byte("not a zip")},
+		},
+		want: config.Dependencies{},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			hdl := &memHandle{
+				name:      "foo",
+				version:   "1.0-r0",
+				files:     c.files,
+				relatives: c.relatives,
+				deps:      c.deps,
+				resolver:  resolver,
+			}
+			if c.noRepo {
+				hdl.resolver = nil
+			}
+
+			got := config.Dependencies{}
+			if err := Analyze(ctx, hdl, &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("Analyze(): (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"context"
+	"debug/elf"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+// nodeGlobalModules is where `npm install -g` installs packages.
+const nodeGlobalModules = "usr/lib/node_modules"
+
+// nodePackageJSON is the subset of package.json we care about.
+type nodePackageJSON struct {
+	Name    string          `json:"name"`
+	Version string          `json:"version"`
+	Engines json.RawMessage `json:"engines"`
+}
+
+// nodeEngine returns the range of Node.js versions the package supports.
+func (p nodePackageJSON) nodeEngine() string {
+	// Some old packages have an array of engines, which we ignore.
+	var engines map[string]string
+	if json.Unmarshal(p.Engines, &engines) != nil {
+		return ""
+	}
+	return engines["node"]
+}
+
+var (
+	nodeComparatorSpaceRegexp = regexp.MustCompile(`([<>=~^])\s+`)
+	nodeComparatorRegexp      = regexp.MustCompile(`^(<=|>=|<|>|=|~>|~|\^)?v?(\d*)`)
+)
+
+// nodeMinimumMajor returns the lowest major version of Node.js allowed by the
+// semver range r, such as 18 for ">=18.0.0" or "^18 || ^20", if it has one.
+func nodeMinimumMajor(r string) (int, bool) {
+	lowest := -1
+	for _, alt := range strings.Split(r, "||") {
+		alt = nodeComparatorSpaceRegexp.ReplaceAllString(strings.TrimSpace(alt), "$1")
+
+		major := -1
+		for _, comparator := range strings.Fields(alt) {
+			m := nodeComparatorRegexp.FindStringSubmatch(comparator)
+			if m[1] == "<" || m[1] == "<=" {
+				continue
+			}
+			major, _ = strconv.Atoi(m[2])
+			break
+		}
+
+		// This alternative allows any version.
+		if major <= 0 {
+			return 0, false
+		}
+
+		if lowest < 0 || major < lowest {
+			lowest = major
+		}
+	}
+
+	return lowest, lowest > 0
+}
+
+// nodeModuleDir reports whether dir is the directory of a package installed
+// in a node_modules directory, and whether it is installed globally.
+func nodeModuleDir(dir string) (bool, bool) {
+	parent := path.Dir(dir)
+	if strings.HasPrefix(path.Base(parent), "@") {
+		parent = path.Dir(parent)
+	}
+	if path.Base(parent) != "node_modules" {
+		return false, false
+	}
+	return true, parent == nodeGlobalModules
+}
+
+func readNodePackageJSON(fsys fs.FS, name string) (nodePackageJSON, error) {
+	var pkg nodePackageJSON
+
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return pkg, err
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return pkg, err
+	}
+
+	return pkg, nil
+}
+
+// generateNodeDeps generates npm:name provides for Node.js packages installed
+// globally, vendored npm:name entries for those installed in the node_modules
+// of an application, a nodejs dependency for the Node.js versions they
+// support, and so: dependencies for their native addons.
+func generateNodeDeps(ctx context.Context, hdl SCAHandle, generated *config.Dependencies, extraLibDirs []string) error {
+	log := clog.FromContext(ctx)
+	log.Infof("scanning for node.js packages...")
+
+	fsys, err := hdl.Filesystem()
+	if err != nil {
+		return err
+	}
+
+	// Whether we found any package or application that needs Node.js, and
+	// the lowest major version they all support.
+	needsNode := false
+	nodeMajor := 0
+	var nodeFile, nodeEngine string
+
+	if err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		if path.Ext(p) == ".node" {
+			return generateNodeAddonDeps(ctx, hdl, fsys, p, append(slices.Clone(libDirs), extraLibDirs...), generated)
+		}
+
+		if path.Base(p) != "package.json" {
+			return nil
+		}
+
+		dir := path.Dir(p)
+		module, global := nodeModuleDir(dir)
+		if !module {
+			// This is an application if it ships its own dependencies.
+			if fi, err := fsys.Stat(path.Join(dir, "node_modules")); err != nil || !fi.IsDir() {
+				return nil
+			}
+		}
+
+		pkg, err := readNodePackageJSON(fsys, p)
+		if err != nil {
+			log.Warnf("Unable to read %s: %v", p, err)
+			return nil
+		}
+
+		if module {
+			if pkg.Name == "" {
+				return nil
+			}
+
+			if !global {
+				log.Infof("  found vendored npm package %s for %s", pkg.Name, p)
+				addVendored(ctx, generated, fmt.Sprintf("npm:%s=%s", pkg.Name, hdl.Version()), p, "npm package "+pkg.Name+" in application node_modules")
+				return nil
+			}
+
+			log.Infof("  found npm package %s for %s", pkg.Name, p)
+			addProvides(ctx, generated, fmt.Sprintf("npm:%s=%s", pkg.Name, hdl.Version()), p, "npm package "+pkg.Name+" in "+nodeGlobalModules)
+		}
+
+		if !needsNode {
+			nodeFile = p
+		}
+		needsNode = true
+		if major, ok := nodeMinimumMajor(pkg.nodeEngine()); ok && major > nodeMajor {
+			log.Infof("  %s requires node %s", p, pkg.nodeEngine())
+			nodeMajor = major
+			nodeFile, nodeEngine = p, pkg.nodeEngine()
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Nothing to do...
+	if !needsNode || strings.HasPrefix(hdl.PackageName(), "nodejs") {
+		return nil
+	}
+
+	// Do not add a Node.js dependency if one already exists.
+	for _, dep := range hdl.BaseDependencies().Runtime {
+		if strings.HasPrefix(dep, "nodejs") {
+			log.Warnf("%s: Node.js dependency %q already specified, consider removing it in favor of SCA-generated dependency", hdl.PackageName(), dep)
+			return nil
+		}
+	}
+
+	nodeDep, reason := "nodejs", "node.js package"
+	if nodeMajor > 0 {
+		nodeDep = fmt.Sprintf("nodejs>=%d", nodeMajor)
+		reason = "engines.node " + nodeEngine
+	}
+
+	log.Infof("  found node.js package, generating %s dependency", nodeDep)
+	addRuntime(ctx, generated, nodeDep, nodeFile, reason)
+
+	return nil
+}
+
+// generateNodeAddonDeps generates so: dependencies for the libraries needed by
+// a native Node.js addon.
+func generateNodeAddonDeps(ctx context.Context, hdl SCAHandle, fsys SCAFS, p string, defaultDirs []string, generated *config.Dependencies) error {
+	log := clog.FromContext(ctx)
+
+	f, err := fsys.Open(p)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	ra, ok := f.(io.ReaderAt)
+	if !ok {
+		return nil
+	}
+
+	ef, err := elf.NewFile(ra)
+	if err != nil {
+		return nil
+	}
+	defer ef.Close()
+
+	log.Infof("  found native node.js addon %s", p)
+
+	libs, err := neededLibraries(ctx, fsys, ef, p, defaultDirs)
+	if err != nil {
+		log.Warnf("Unable to read the libraries needed by %s: %v", p, err)
+		return nil
+	}
+
+	return generateNeededLibraryDeps(ctx, hdl, p, libs, generated)
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/chainguard-dev/clog/slogtest"
+	"github.com/google/go-cmp/cmp"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+func TestNodeSca(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	// Any shared object will do as a native addon.
+	th := handleFromApk(ctx, t, "libcap-2.69-r0.apk", "libcap.yaml")
+	defer th.exp.Close()
+	addon, err := fs.ReadFile(th.exp.TarFS, "usr/lib/libpsx.so.2.69")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name  string
+		files fstest.MapFS
+		deps  config.Dependencies
+		want  config.Dependencies
+	}{{
+		name: "global",
+		files: fstest.MapFS{
+			"usr/lib/node_modules/foo/package.json":                      {Data: []byte(`{"name": "foo", "version": "1.2.3", "engines": {"node": ">= 18.0.0"}}`)},
+			"usr/lib/node_modules/foo/build/Release/foo.node":            {Data: addon},
+			"usr/lib/node_modules/foo/node_modules/bar/package.json":     {Data: []byte(`{"name": "bar", "engines": {"node": ">=20"}}`)},
+			"usr/lib/node_modules/@scope/baz/package.json":               {Data: []byte(`{"name": "@scope/baz", "engines": {"node": "^16 || ^20"}}`)},
+			"usr/lib/node_modules/@scope/baz/lib/package.json":           {Data: []byte(`{"type": "module"}`)},
+			"usr/lib/node_modules/@scope/baz/node_modules/.bin/whatever": {},
+		},
+		want: config.Dependencies{
+			Runtime:  []string{"nodejs>=18", "so:ld-linux-aarch64.so.1", "so:libc.so.6"},
+			Provides: []string{"npm:@scope/baz=1.0-r0", "npm:foo=1.0-r0"},
+			Vendored: []string{"npm:bar=1.0-r0"},
+		},
+	}, {
+		name: "application",
+		files: fstest.MapFS{
+			"usr/share/app/package.json":                  {Data: []byte(`{"name": "app", "engines": ["node >= 0.4"]}`)},
+			"usr/share/app/node_modules/qux/package.json": {Data: []byte(`{"name": "qux"}`)},
+		},
+		want: config.Dependencies{
+			Runtime:  []string{"nodejs"},
+			Vendored: []string{"npm:qux=1.0-r0"},
+		},
+	}, {
+		name: "nodejs already specified",
+		files: fstest.MapFS{
+			"usr/lib/node_modules/foo/package.json": {Data: []byte(`{"name": "foo"}`)},
+		},
+		deps: config.Dependencies{Runtime: []string{"nodejs-22"}},
+		want: config.Dependencies{
+			Provides: []string{"npm:foo=1.0-r0"},
+		},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			hdl := &memHandle{
+				name:    "foo",
+				version: "1.0-r0",
+				files:   c.files,
+				deps:    c.deps,
+			}
+
+			got := config.Dependencies{}
+			if err := Analyze(ctx, hdl, &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("Analyze(): (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNodeMinimumMajor(t *testing.T) {
+	for r, want := range map[string]int{
+		">=18":       18,
+		">= 18.0.0":  18,
+		"^18 || ^20": 18,
+		"~16.14.0":   16,
+		"18.x":       18,
+		"v20.1.0":    20,
+		">=14 <21":   14,
+		"<20 >=16":   16,
+		"18 - 20":    18,
+		"":           0,
+		"*":          0,
+		"<20":        0,
+		"^18 || *":   0,
+		">=0.10":     0,
+	} {
+		got, ok := nodeMinimumMajor(r)
+		if !ok {
+			got = 0
+		}
+		if got != want {
+			t.Errorf("nodeMinimumMajor(%q): want %d, got %d", r, want, got)
+		}
+	}
+}
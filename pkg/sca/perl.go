@@ -0,0 +1,295 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/chainguard-dev/clog"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+var perlDirs = []string{"usr/lib/perl5/", "usr/share/perl5/"}
+
+var (
+	perlIdentRegexp   = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+	perlModuleRegexp  = regexp.MustCompile(`[A-Za-z_]\w*(?:::\w+)*`)
+	perlRequireRegexp = regexp.MustCompile(`^\s*(?:use|require)\s+([A-Za-z_]\w*(?:::\w+)*)(.*)`)
+	perlVersionRegexp = regexp.MustCompile(`^(\d+)\.(\d+)`)
+)
+
+// perlModuleName returns the name of the Perl module installed at path, such
+// as Foo::Bar for usr/share/perl5/vendor_perl/Foo/Bar.pm.
+func perlModuleName(path string) (string, bool) {
+	if filepath.Ext(path) != ".pm" {
+		return "", false
+	}
+
+	for _, dir := range perlDirs {
+		rest, ok := strings.CutPrefix(path, dir)
+		if !ok {
+			continue
+		}
+
+		parts := strings.Split(strings.TrimSuffix(rest, ".pm"), "/")
+		if len(parts) > 1 && slices.Contains([]string{"vendor_perl", "site_perl", "core_perl"}, parts[0]) {
+			parts = parts[1:]
+		}
+
+		// Skip the version and architecture directories, such as 5.40 or
+		// x86_64-linux-thread-multi, which can't be part of a module name.
+		for len(parts) > 1 && !perlIdentRegexp.MatchString(parts[0]) {
+			parts = parts[1:]
+		}
+
+		// Autoloaded code and XS objects live in auto/, but aren't modules.
+		if len(parts) > 1 && parts[0] == "auto" {
+			return "", false
+		}
+
+		for _, p := range parts {
+			if !perlIdentRegexp.MatchString(p) {
+				return "", false
+			}
+		}
+
+		return strings.Join(parts, "::"), true
+	}
+
+	return "", false
+}
+
+// perlRequires returns the modules loaded with `use` or `require` by Perl
+// source read from r. Pragmas are left out, except for the parent classes
+// loaded by `use parent` and `use base`.
+func perlRequires(r io.Reader) ([]string, error) {
+	var modules []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	inPod := false
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Skip POD documentation, which often contains example code.
+		if strings.HasPrefix(line, "=") && len(line) > 1 && unicode.IsLetter(rune(line[1])) {
+			inPod = !strings.HasPrefix(line, "=cut")
+			continue
+		}
+		if inPod {
+			continue
+		}
+
+		if line == "__END__" || line == "__DATA__" {
+			break
+		}
+
+		m := perlRequireRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		name, rest := m[1], m[2]
+		switch {
+		case name == "parent" || name == "base":
+			if strings.Contains(rest, "-norequire") {
+				continue
+			}
+			rest, _, _ = strings.Cut(rest, ";")
+			for _, parent := range perlModuleRegexp.FindAllString(rest, -1) {
+				if isPerlModule(parent) {
+					modules = append(modules, parent)
+				}
+			}
+		case isPerlModule(name):
+			modules = append(modules, name)
+		}
+	}
+
+	return modules, scanner.Err()
+}
+
+// isPerlModule reports whether name is a module rather than a pragma, which
+// by convention are all lowercase.
+func isPerlModule(name string) bool {
+	return name != "" && !unicode.IsLower(rune(name[0]))
+}
+
+// perlModules returns the Perl modules installed in fsys, mapped to their
+// paths, and whether fsys contains any XS objects.
+func perlModules(fsys fs.FS) (map[string]string, bool, error) {
+	modules := map[string]string{}
+	xs := false
+
+	if err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() || !slices.ContainsFunc(perlDirs, func(dir string) bool {
+			return strings.HasPrefix(path, dir)
+		}) {
+			return nil
+		}
+
+		if filepath.Ext(path) == ".so" {
+			xs = true
+		}
+
+		if name, ok := perlModuleName(path); ok {
+			modules[name] = path
+		}
+
+		return nil
+	}); err != nil {
+		return nil, false, err
+	}
+
+	return modules, xs, nil
+}
+
+// generatePerlDeps generates perl(Module::Name) provides for packages which
+// ship Perl modules, perl(Module::Name) dependencies on the modules they load,
+// and a dependency on perl itself.
+func generatePerlDeps(ctx context.Context, hdl SCAHandle, generated *config.Dependencies, extraLibDirs []string) error {
+	log := clog.FromContext(ctx)
+	log.Infof("scanning for perl modules...")
+
+	fsys, err := hdl.Filesystem()
+	if err != nil {
+		return err
+	}
+
+	modules, xs, err := perlModules(fsys)
+	if err != nil {
+		return err
+	}
+
+	// Nothing to do...
+	if len(modules) == 0 && !xs {
+		return nil
+	}
+
+	// The modules required, and the first of our modules requiring each.
+	requires := map[string]string{}
+	var firstModule string
+	for _, name := range slices.Sorted(maps.Keys(modules)) {
+		path := modules[name]
+		if firstModule == "" {
+			firstModule = path
+		}
+		log.Infof("  found perl module %s for %s", name, path)
+		addProvides(ctx, generated, fmt.Sprintf("perl(%s)=%s", name, hdl.Version()), path, "perl module "+name)
+
+		f, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		deps, err := perlRequires(f)
+		f.Close()
+		if err != nil {
+			log.Warnf("Unable to read perl module (%s): %v", path, err)
+			continue
+		}
+		for _, dep := range deps {
+			if _, ok := requires[dep]; !ok {
+				requires[dep] = path
+			}
+		}
+	}
+
+	// Modules provided by the other packages being built can be depended
+	// upon, even though they aren't in any repository yet.
+	relatives := map[string]bool{}
+	for _, pkg := range hdl.RelativeNames() {
+		if pkg == hdl.PackageName() {
+			continue
+		}
+
+		rfs, err := hdl.FilesystemForRelative(pkg)
+		if err != nil {
+			return err
+		}
+
+		rmodules, _, err := perlModules(rfs)
+		if err != nil {
+			return err
+		}
+		for name := range rmodules {
+			relatives[name] = true
+		}
+	}
+
+	resolver := hdl.PkgResolver()
+	for _, dep := range slices.Sorted(maps.Keys(requires)) {
+		if _, ok := modules[dep]; ok {
+			continue
+		}
+
+		// Most modules are part of perl itself, which doesn't provide them by
+		// name, so only depend on modules we know where to find.
+		if !relatives[dep] && !isResolvable(resolver, fmt.Sprintf("perl(%s)", dep)) {
+			log.Debugf("  nothing provides perl(%s), assuming it is part of perl", dep)
+			continue
+		}
+
+		log.Infof("  found perl dependency perl(%s)", dep)
+		addRuntime(ctx, generated, fmt.Sprintf("perl(%s)", dep), requires[dep], "use "+dep)
+	}
+
+	// perl itself and its subpackages ship modules too.
+	if slices.Contains(hdl.RelativeNames(), "perl") {
+		return nil
+	}
+
+	// Do not add a Perl dependency if one already exists.
+	for _, dep := range hdl.BaseDependencies().Runtime {
+		name := dep
+		if i := strings.IndexAny(dep, "<>=~"); i >= 0 {
+			name = dep[:i]
+		}
+		if name == "perl" {
+			log.Warnf("%s: Perl dependency %q already specified, consider removing it in favor of SCA-generated dependency", hdl.PackageName(), dep)
+			return nil
+		}
+	}
+
+	// XS modules are only compatible with the X.Y series of perl they were
+	// built against.
+	perlDep, reason := "perl", "perl module"
+	if xs {
+		reason = "perl XS module"
+		if m := perlVersionRegexp.FindStringSubmatch(hdl.InstalledPackages()["perl"]); m != nil {
+			perlDep = fmt.Sprintf("perl~%s.%s", m[1], m[2])
+		}
+	}
+
+	log.Infof("  found perl module, generating %s dependency", perlDep)
+	addRuntime(ctx, generated, perlDep, firstModule, reason)
+
+	return nil
+}
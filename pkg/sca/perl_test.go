@@ -0,0 +1,137 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/chainguard-dev/clog/slogtest"
+	"github.com/google/go-cmp/cmp"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+const perlFooBar = `package Foo::Bar;
+use strict;
+use warnings;
+use 5.010;
+use Carp qw(croak);
+use Foo::Baz;
+use parent -norequire, 'Foo::Internal';
+use base qw(Foo::Base);
+require Foo::XS;
+
+=head1 SYNOPSIS
+
+  use Foo::Example;
+
+=cut
+
+sub new { bless {}, shift }
+
+1;
+__END__
+use Foo::After;
+`
+
+func TestPerlSca(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		files fstest.MapFS
+		deps  config.Dependencies
+		want  config.Dependencies
+	}{{
+		name: "pure perl",
+		files: fstest.MapFS{
+			"usr/share/perl5/vendor_perl/Foo/Bar.pm": {Data: []byte(perlFooBar)},
+			"usr/share/perl5/vendor_perl/Foo/Baz.pm": {Data: []byte("package Foo::Baz;\n1;\n")},
+		},
+		want: config.Dependencies{
+			Runtime: []string{"perl", "perl(Foo::Base)"},
+			Provides: []string{
+				"perl(Foo::Bar)=1.0-r0",
+				"perl(Foo::Baz)=1.0-r0",
+			},
+		},
+	}, {
+		name: "xs",
+		files: fstest.MapFS{
+			"usr/lib/perl5/vendor_perl/x86_64-linux-thread-multi/Foo/XS.pm":         {Data: []byte("package Foo::XS;\nuse XSLoader;\n1;\n")},
+			"usr/lib/perl5/vendor_perl/x86_64-linux-thread-multi/auto/Foo/XS/XS.so": {Data: []byte("not really")},
+		},
+		want: config.Dependencies{
+			Runtime:  []string{"perl~5.40"},
+			Provides: []string{"perl(Foo::XS)=1.0-r0"},
+		},
+	}, {
+		name: "perl already specified",
+		files: fstest.MapFS{
+			"usr/share/perl5/vendor_perl/Foo/Baz.pm": {Data: []byte("package Foo::Baz;\n1;\n")},
+		},
+		deps: config.Dependencies{Runtime: []string{"perl>=5.36"}},
+		want: config.Dependencies{
+			Provides: []string{"perl(Foo::Baz)=1.0-r0"},
+		},
+	}, {
+		name: "no perl modules",
+		files: fstest.MapFS{
+			"usr/share/doc/foo/README.pm": {Data: []byte("use Foo::Bar;\n")},
+		},
+		want: config.Dependencies{},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := slogtest.Context(t)
+			hdl := &memHandle{
+				name:    "perl-foo",
+				version: "1.0-r0",
+				files:   c.files,
+				relatives: map[string]fstest.MapFS{
+					"perl-foo-base": {
+						"usr/share/perl5/vendor_perl/Foo/Base.pm": {Data: []byte("package Foo::Base;\n1;\n")},
+					},
+				},
+				deps:      c.deps,
+				installed: map[string]string{"perl": "5.40.2-r0"},
+			}
+
+			got := config.Dependencies{}
+			if err := Analyze(ctx, hdl, &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("Analyze(): (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPerlModuleName(t *testing.T) {
+	for path, want := range map[string]string{
+		"usr/share/perl5/vendor_perl/Foo/Bar.pm":                        "Foo::Bar",
+		"usr/lib/perl5/core_perl/Carp.pm":                               "Carp",
+		"usr/lib/perl5/site_perl/5.40.0/Foo.pm":                         "Foo",
+		"usr/lib/perl5/vendor_perl/x86_64-linux-thread-multi/Foo/XS.pm": "Foo::XS",
+		"usr/lib/perl5/vendor_perl/auto/Foo/autosplit.pm":               "",
+		"usr/lib/perl5/vendor_perl/Foo/Bar.pl":                          "",
+		"usr/share/doc/Foo.pm":                                          "",
+	} {
+		got, _ := perlModuleName(path)
+		if got != want {
+			t.Errorf("perlModuleName(%q): want %q, got %q", path, want, got)
+		}
+	}
+}
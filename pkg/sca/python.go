@@ -0,0 +1,540 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/textproto"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/chainguard-dev/clog"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+// pythonDist is a Python distribution installed in a site-packages directory.
+type pythonDist struct {
+	// The X.Y version of Python it is installed for.
+	pythonVer string
+	// The path of its .dist-info directory.
+	dir string
+	// Its name as written in its metadata, and normalized.
+	name, normalized string
+	// The raw Requires-Dist entries of its metadata.
+	requires []string
+}
+
+// provide returns the name other distributions depend on this one with.
+func (d pythonDist) provide() string {
+	return pythonDistDep(d.pythonVer, d.normalized)
+}
+
+func pythonDistDep(pythonVer, normalized string) string {
+	return fmt.Sprintf("py%s-dist:%s", pythonVer, normalized)
+}
+
+var pythonNameSeparatorRegexp = regexp.MustCompile(`[-_.]+`)
+
+// normalizePythonName normalizes a distribution name as described in PEP 503,
+// so that e.g. Foo_Bar and foo.bar are the same distribution.
+func normalizePythonName(name string) string {
+	return strings.ToLower(pythonNameSeparatorRegexp.ReplaceAllString(name, "-"))
+}
+
+// pythonDists returns the distributions installed in fsys.
+func pythonDists(fsys fs.FS) ([]pythonDist, error) {
+	dirs, err := fs.Glob(fsys, "usr/lib/python[0-9]*/site-packages/*.dist-info")
+	if err != nil {
+		return nil, err
+	}
+
+	var dists []pythonDist
+	for _, dir := range dirs {
+		pythonVer := strings.TrimPrefix(path.Base(path.Dir(path.Dir(dir))), "python")
+		if pythonVer == "" {
+			continue
+		}
+
+		f, err := fsys.Open(path.Join(dir, "METADATA"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		// The metadata is in email header format, followed by the description.
+		hdr, err := textproto.NewReader(bufio.NewReader(f)).ReadMIMEHeader()
+		f.Close()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("reading %s/METADATA: %w", dir, err)
+		}
+
+		name := hdr.Get("Name")
+		if name == "" {
+			continue
+		}
+
+		dists = append(dists, pythonDist{
+			pythonVer:  pythonVer,
+			dir:        dir,
+			name:       name,
+			normalized: normalizePythonName(name),
+			requires:   hdr.Values("Requires-Dist"),
+		})
+	}
+
+	return dists, nil
+}
+
+// missingRecordFiles returns how many of the files listed in the RECORD of
+// dist aren't in fsys.
+func missingRecordFiles(fsys SCAFS, dist pythonDist) (int, error) {
+	f, err := fsys.Open(path.Join(dist.dir, "RECORD"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return 0, fmt.Errorf("reading %s/RECORD: %w", dist.dir, err)
+	}
+
+	sitePackages := path.Dir(dist.dir)
+	missing := 0
+	for _, record := range records {
+		if len(record) == 0 || record[0] == "" {
+			continue
+		}
+
+		name := path.Clean(record[0])
+		if !path.IsAbs(name) {
+			name = path.Join(sitePackages, name)
+		}
+		name = strings.TrimPrefix(name, "/")
+
+		// Bytecode is often left out of packages, or compiled on install.
+		if strings.HasSuffix(name, ".pyc") {
+			continue
+		}
+
+		if _, err := fsys.Stat(name); err != nil {
+			missing++
+		}
+	}
+
+	return missing, nil
+}
+
+// parseRequiresDist splits a Requires-Dist entry such as
+// `foo[bar] (>=1.0) ; python_version < "3.11"` into the normalized name of the
+// distribution and its environment marker.
+func parseRequiresDist(req string) (string, string) {
+	spec, marker, _ := strings.Cut(req, ";")
+	if i := strings.IndexAny(spec, " \t[(<>=!~@"); i >= 0 {
+		spec = spec[:i]
+	}
+	return normalizePythonName(strings.TrimSpace(spec)), strings.TrimSpace(marker)
+}
+
+// pythonMarkerEnv returns the values of the environment marker variables of
+// PEP 508 for Python pythonVer on arch.
+func pythonMarkerEnv(pythonVer, arch string) map[string]string {
+	return map[string]string{
+		"os_name":                        "posix",
+		"sys_platform":                   "linux",
+		"platform_system":                "Linux",
+		"platform_machine":               arch,
+		"platform_release":               "",
+		"platform_version":               "",
+		"platform_python_implementation": "CPython",
+		"implementation_name":            "cpython",
+		"implementation_version":         pythonVer,
+		"python_version":                 pythonVer,
+		"python_full_version":            pythonVer,
+		"extra":                          "",
+	}
+}
+
+// evalPythonMarker reports whether the PEP 508 environment marker holds in env.
+func evalPythonMarker(marker string, env map[string]string) (bool, error) {
+	if marker == "" {
+		return true, nil
+	}
+
+	tokens, err := tokenizePythonMarker(marker)
+	if err != nil {
+		return false, err
+	}
+
+	p := &markerParser{tokens: tokens, env: env}
+	v, err := p.or()
+	if err != nil {
+		return false, err
+	}
+	if p.pos != len(p.tokens) {
+		return false, fmt.Errorf("unexpected %q in marker %q", p.tokens[p.pos].text, marker)
+	}
+
+	return v, nil
+}
+
+type markerToken struct {
+	text   string
+	quoted bool
+}
+
+func tokenizePythonMarker(s string) ([]markerToken, error) {
+	var tokens []markerToken
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, markerToken{text: string(c)})
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in marker %q", s)
+			}
+			tokens = append(tokens, markerToken{text: s[i+1 : i+1+end], quoted: true})
+			i += end + 2
+		case strings.IndexByte("<>=!~", c) >= 0:
+			j := i
+			for j < len(s) && strings.IndexByte("<>=!~", s[j]) >= 0 {
+				j++
+			}
+			tokens = append(tokens, markerToken{text: s[i:j]})
+			i = j
+		default:
+			j := i
+			for j < len(s) && strings.IndexByte(" \t()'\"<>=!~", s[j]) < 0 {
+				j++
+			}
+			tokens = append(tokens, markerToken{text: s[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// markerParser evaluates a tokenized environment marker.
+type markerParser struct {
+	tokens []markerToken
+	pos    int
+	env    map[string]string
+}
+
+func (p *markerParser) peek() (markerToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return markerToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *markerParser) next() (markerToken, error) {
+	t, ok := p.peek()
+	if !ok {
+		return t, fmt.Errorf("unexpected end of marker")
+	}
+	p.pos++
+	return t, nil
+}
+
+func (p *markerParser) keyword(kw string) bool {
+	if t, ok := p.peek(); ok && !t.quoted && t.text == kw {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *markerParser) or() (bool, error) {
+	v, err := p.and()
+	if err != nil {
+		return false, err
+	}
+	for p.keyword("or") {
+		w, err := p.and()
+		if err != nil {
+			return false, err
+		}
+		v = v || w
+	}
+	return v, nil
+}
+
+func (p *markerParser) and() (bool, error) {
+	v, err := p.expr()
+	if err != nil {
+		return false, err
+	}
+	for p.keyword("and") {
+		w, err := p.expr()
+		if err != nil {
+			return false, err
+		}
+		v = v && w
+	}
+	return v, nil
+}
+
+func (p *markerParser) expr() (bool, error) {
+	if p.keyword("(") {
+		v, err := p.or()
+		if err != nil {
+			return false, err
+		}
+		if !p.keyword(")") {
+			return false, fmt.Errorf("missing closing parenthesis in marker")
+		}
+		return v, nil
+	}
+
+	lhs, err := p.value()
+	if err != nil {
+		return false, err
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return false, err
+	}
+	if !op.quoted && op.text == "not" {
+		if !p.keyword("in") {
+			return false, fmt.Errorf("expected in after not in marker")
+		}
+		op.text = "not in"
+	}
+
+	rhs, err := p.value()
+	if err != nil {
+		return false, err
+	}
+
+	return compareMarkerValues(lhs, op.text, rhs)
+}
+
+func (p *markerParser) value() (string, error) {
+	t, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if t.quoted {
+		return t.text, nil
+	}
+	v, ok := p.env[t.text]
+	if !ok {
+		return "", fmt.Errorf("unknown marker variable %q", t.text)
+	}
+	return v, nil
+}
+
+func compareMarkerValues(lhs, op, rhs string) (bool, error) {
+	switch op {
+	case "in":
+		return strings.Contains(rhs, lhs), nil
+	case "not in":
+		return !strings.Contains(rhs, lhs), nil
+	case "===":
+		return lhs == rhs, nil
+	}
+
+	a, aok := parsePythonVersion(lhs)
+	b, bok := parsePythonVersion(rhs)
+	if !aok || !bok {
+		switch op {
+		case "==":
+			return lhs == rhs, nil
+		case "!=":
+			return lhs != rhs, nil
+		case "<":
+			return lhs < rhs, nil
+		case "<=":
+			return lhs <= rhs, nil
+		case ">":
+			return lhs > rhs, nil
+		case ">=":
+			return lhs >= rhs, nil
+		}
+		return false, fmt.Errorf("unsupported marker operator %q", op)
+	}
+
+	c := comparePythonVersions(a, b)
+	switch op {
+	case "==":
+		// A version with fewer components matches as a prefix, so that
+		// python_full_version == "3.12" holds for 3.12.
+		return comparePythonVersions(a[:min(len(a), len(b))], b) == 0, nil
+	case "!=":
+		return comparePythonVersions(a[:min(len(a), len(b))], b) != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	case "~=":
+		if len(b) < 2 {
+			return false, fmt.Errorf("invalid compatible release %q", rhs)
+		}
+		prefix := b[:len(b)-1]
+		return c >= 0 && comparePythonVersions(a[:min(len(a), len(prefix))], prefix) == 0, nil
+	}
+
+	return false, fmt.Errorf("unsupported marker operator %q", op)
+}
+
+// parsePythonVersion parses the release segment of a version, such as 3.12.1.
+func parsePythonVersion(v string) ([]int, bool) {
+	if v == "" {
+		return nil, false
+	}
+	var out []int
+	for _, part := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		out = append(out, n)
+	}
+	return out, true
+}
+
+func comparePythonVersions(a, b []int) int {
+	for i := range max(len(a), len(b)) {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// generatePythonDistDeps generates py3.X-dist:name provides for the Python
+// distributions installed in fsys, and dependencies on the distributions
+// they require.
+func generatePythonDistDeps(ctx context.Context, hdl SCAHandle, fsys SCAFS, generated *config.Dependencies) error {
+	log := clog.FromContext(ctx)
+
+	dists, err := pythonDists(fsys)
+	if err != nil {
+		return err
+	}
+	if len(dists) == 0 {
+		return nil
+	}
+
+	provided := map[string]bool{}
+	for _, dist := range dists {
+		log.Infof("  found python distribution %s for %s", dist.name, dist.dir)
+		addProvides(ctx, generated, fmt.Sprintf("%s=%s", dist.provide(), hdl.Version()), dist.dir, "python distribution "+dist.name)
+		provided[dist.provide()] = true
+
+		missing, err := missingRecordFiles(fsys, dist)
+		if err != nil {
+			log.Warnf("Unable to read RECORD of %s: %v", dist.name, err)
+		} else if missing > 0 {
+			log.Warnf("%s: python distribution %s is missing %d file(s) listed in its RECORD", hdl.PackageName(), dist.name, missing)
+		}
+	}
+
+	// Distributions shipped by the other packages being built can be
+	// depended upon, even though they aren't in any repository yet.
+	relatives := map[string]bool{}
+	for _, pkg := range hdl.RelativeNames() {
+		if pkg == hdl.PackageName() {
+			continue
+		}
+
+		rfs, err := hdl.FilesystemForRelative(pkg)
+		if err != nil {
+			return err
+		}
+
+		rdists, err := pythonDists(rfs)
+		if err != nil {
+			return err
+		}
+		for _, dist := range rdists {
+			relatives[dist.provide()] = true
+		}
+	}
+
+	resolver := hdl.PkgResolver()
+	for _, dist := range dists {
+		env := pythonMarkerEnv(dist.pythonVer, hdl.Arch())
+
+		for _, req := range dist.requires {
+			name, marker := parseRequiresDist(req)
+			if name == "" {
+				continue
+			}
+
+			ok, err := evalPythonMarker(marker, env)
+			if err != nil {
+				log.Warnf("%s: unable to evaluate requirement %q of %s: %v", hdl.PackageName(), req, dist.name, err)
+				continue
+			}
+			if !ok {
+				log.Debugf("  skipping requirement %q of %s", req, dist.name)
+				continue
+			}
+
+			dep := pythonDistDep(dist.pythonVer, name)
+			if provided[dep] {
+				continue
+			}
+
+			if !relatives[dep] && !isResolvable(resolver, dep) {
+				if resolver != nil {
+					log.Warnf("%s: nothing provides %s, required by python distribution %s", hdl.PackageName(), dep, dist.name)
+				}
+				continue
+			}
+
+			log.Infof("  found python dependency %s for %s", dep, dist.name)
+			addRuntime(ctx, generated, dep, dist.dir, "Requires-Dist "+req)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/chainguard-dev/clog/slogtest"
+	"github.com/google/go-cmp/cmp"
+
+	"chainguard.dev/melange/pkg/config"
+)
+
+const fooBarMetadata = `Metadata-Version: 2.1
+Name: Foo_Bar
+Version: 1.0
+Requires-Dist: requests (>=2.0)
+Requires-Dist: tomli ; python_version < "3.11"
+Requires-Dist: pywin32 ; sys_platform == "win32"
+Requires-Dist: uvloop ; platform_machine == "x86_64" and implementation_name == "cpython"
+Requires-Dist: pytest ; extra == "test"
+Requires-Dist: foo.plugin
+Requires-Dist: Foo-Bar-Core[speedups] >=1.0
+
+Foo Bar
+=======
+
+Requires-Dist: not-a-header
+`
+
+func TestPythonDistSca(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	const site = "usr/lib/python3.12/site-packages/"
+	hdl := &memHandle{
+		name:    "py3.12-foo-bar",
+		version: "1.0-r0",
+		arch:    "x86_64",
+		files: fstest.MapFS{
+			site + "foo_bar/__init__.py":               {},
+			site + "foo_bar-1.0.dist-info/METADATA":    {Data: []byte(fooBarMetadata)},
+			site + "foo_bar-1.0.dist-info/RECORD":      {Data: []byte("foo_bar/__init__.py,sha256=abc,0\nfoo_bar/missing.py,,\nfoo_bar-1.0.dist-info/RECORD,,\n")},
+			site + "foo.plugin-1.0.dist-info/METADATA": {Data: []byte("Name: foo.plugin\nVersion: 1.0\n")},
+		},
+		relatives: map[string]fstest.MapFS{
+			"py3.12-foo-bar-core": {
+				site + "foo_bar_core-1.0.dist-info/METADATA": {Data: []byte("Name: foo-bar-core\n")},
+				site + "uvloop-1.0.dist-info/METADATA":       {Data: []byte("Name: uvloop\n")},
+				site + "tomli-1.0.dist-info/METADATA":        {Data: []byte("Name: tomli\n")},
+			},
+		},
+	}
+
+	got := config.Dependencies{}
+	if err := Analyze(ctx, hdl, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	// requests is left out, as nothing is known to provide it.
+	want := config.Dependencies{
+		Runtime: []string{
+			"py3.12-dist:foo-bar-core",
+			"py3.12-dist:uvloop",
+			"python-3.12-base",
+		},
+		Provides: []string{
+			"py3.12-dist:foo-bar=1.0-r0",
+			"py3.12-dist:foo-plugin=1.0-r0",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Analyze(): (-want, +got):\n%s", diff)
+	}
+}
+
+func TestEvalPythonMarker(t *testing.T) {
+	env := pythonMarkerEnv("3.12", "aarch64")
+
+	for marker, want := range map[string]bool{
+		``:                                      true,
+		`python_version >= "3.8"`:               true,
+		`python_version < "3.11"`:               false,
+		`python_version == "3.12"`:              true,
+		`python_full_version != "3.12"`:         false,
+		`python_version ~= "3.10"`:              true,
+		`"3.9" < python_version`:                true,
+		`sys_platform == 'linux'`:               true,
+		`platform_machine == "x86_64"`:          false,
+		`platform_machine in "aarch64 arm64"`:   true,
+		`platform_machine not in "x86_64 i686"`: true,
+		`extra == "test"`:                       false,
+		`os_name == "nt" or (sys_platform == "linux" and python_version > "3")`: true,
+		`implementation_name == "pypy" and python_version >= "3.8"`:             false,
+	} {
+		got, err := evalPythonMarker(marker, env)
+		if err != nil {
+			t.Errorf("evalPythonMarker(%q): %v", marker, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("evalPythonMarker(%q): want %t, got %t", marker, want, got)
+		}
+	}
+
+	for _, marker := range []string{
+		`python_version >=`,
+		`unknown_var == "1"`,
+		`(python_version == "3.12"`,
+		`python_version == "3.12`,
+	} {
+		if _, err := evalPythonMarker(marker, env); err == nil {
+			t.Errorf("evalPythonMarker(%q): want error", marker)
+		}
+	}
+}
+
+func TestNormalizePythonName(t *testing.T) {
+	for name, want := range map[string]string{
+		"Foo_Bar":        "foo-bar",
+		"foo.bar":        "foo-bar",
+		"foo--_.bar":     "foo-bar",
+		"zope.interface": "zope-interface",
+	} {
+		if got := normalizePythonName(name); got != want {
+			t.Errorf("normalizePythonName(%q): want %q, got %q", name, want, got)
+		}
+	}
+}
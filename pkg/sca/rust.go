@@ -0,0 +1,102 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"debug/elf"
+	"encoding/json"
+	"io"
+
+	"github.com/chainguard-dev/clog"
+)
+
+// cargoAuditableSection is the section `cargo auditable` embeds the
+// dependency tree of Rust binaries in.
+const cargoAuditableSection = ".dep-v0"
+
+// The largest decompressed dependency tree we read.
+const maxCargoAuditableSize = 64 << 20
+
+// cargoAuditableInfo is the zlib-compressed JSON `cargo auditable` embeds.
+type cargoAuditableInfo struct {
+	Packages []struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		// Source is crates.io, git, local or registry.
+		Source string `json:"source"`
+		// Kind is runtime, unless the crate is only used at build time.
+		Kind string `json:"kind"`
+		Root bool   `json:"root"`
+	} `json:"packages"`
+}
+
+// findRustComponents returns the crates a Rust binary built with
+// `cargo auditable` was built from.
+func findRustComponents(ctx context.Context, path string, _ io.ReaderAt, ef *elf.File) ([]Component, error) {
+	log := clog.FromContext(ctx)
+
+	sec := ef.Section(cargoAuditableSection)
+	if sec == nil {
+		return nil, nil
+	}
+
+	data, err := sec.Data()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("  found rust binary %s built with cargo auditable", path)
+
+	return parseCargoAuditable(path, data)
+}
+
+// parseCargoAuditable returns the crates listed in the `cargo auditable`
+// section of the Rust binary at path, leaving out those only used to build it.
+func parseCargoAuditable(path string, data []byte) ([]Component, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var info cargoAuditableInfo
+	if err := json.NewDecoder(io.LimitReader(zr, maxCargoAuditableSize)).Decode(&info); err != nil {
+		return nil, err
+	}
+
+	var components []Component
+	for _, pkg := range info.Packages {
+		if pkg.Kind == "build" {
+			continue
+		}
+
+		c := Component{
+			Type:    "cargo",
+			Name:    pkg.Name,
+			Version: pkg.Version,
+			Path:    path,
+			Main:    pkg.Root,
+		}
+		if pkg.Source != "" && pkg.Source != "crates.io" {
+			c.Notes = append(c.Notes, "source "+pkg.Source)
+		}
+		components = append(components, c)
+	}
+
+	return components, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 Chainguard, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sca
+
+import (
+	"bytes"
+	"compress/zlib"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCargoAuditable(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write([]byte(`{"packages": [
+		{"name": "foo", "version": "0.1.0", "source": "local", "dependencies": [1, 2, 3], "root": true},
+		{"name": "serde", "version": "1.0.210", "source": "crates.io"},
+		{"name": "cc", "version": "1.1.0", "source": "crates.io", "kind": "build"},
+		{"name": "bar", "version": "0.2.0", "source": "git"}
+	]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parseCargoAuditable("usr/bin/foo", buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Component{{
+		Type: "cargo", Name: "foo", Version: "0.1.0", Path: "usr/bin/foo", Main: true,
+		Notes: []string{"source local"},
+	}, {
+		Type: "cargo", Name: "serde", Version: "1.0.210", Path: "usr/bin/foo",
+	}, {
+		Type: "cargo", Name: "bar", Version: "0.2.0", Path: "usr/bin/foo",
+		Notes: []string{"source git"},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseCargoAuditable(): (-want, +got):\n%s", diff)
+	}
+
+	if _, err := parseCargoAuditable("usr/bin/foo", []byte("not zlib")); err == nil {
+		t.Error("parseCargoAuditable(): want error for corrupt section")
+	}
+}
@@ -60,6 +60,9 @@ type SCAHandle interface {
 	// Version returns the version and epoch of the package being analyzed.
 	Version() string
 
+	// Arch returns the APK architecture of the package being analyzed.
+	Arch() string
+
 	// FilesystemForRelative returns a usable filesystem representing the package
 	// contents for a given package name.
 	FilesystemForRelative(pkgName string) (SCAFS, error)
@@ -240,7 +243,7 @@ func generateCmdProviders(ctx context.Context, hdl SCAHandle, generated *config.
 			if isInDir(path, []string{"bin/", "sbin/", "usr/bin/", "usr/sbin/"}) {
 				basename := filepath.Base(path)
 				log.Infof("  found command %s", path)
-				generated.Provides = append(generated.Provides, fmt.Sprintf("cmd:%s=%s", basename, hdl.Version()))
+				addProvides(ctx, generated, fmt.Sprintf("cmd:%s=%s", basename, hdl.Version()), path, "executable in "+filepath.Dir(path))
 			}
 		}
 
@@ -252,6 +255,16 @@ func generateCmdProviders(ctx context.Context, hdl SCAHandle, generated *config.
 	return nil
 }
 
+// isResolvable reports whether a package in the repositories of resolver
+// provides name.
+func isResolvable(resolver *apk.PkgResolver, name string) bool {
+	if resolver == nil {
+		return false
+	}
+	_, err := resolver.ResolvePackage(name, map[*apk.RepositoryPackage]string{})
+	return err == nil
+}
+
 // findInterpreter looks for the PT_INTERP header and extracts the interpreter so that it
 // may be used as a dependency.
 func findInterpreter(bin *elf.File) (string, error) {
@@ -530,14 +543,115 @@ func processSymlinkSo(ctx context.Context, hdl SCAHandle, path string, generated
 		for _, soname := range sonames {
 			log.Infof("  found soname %s for %s", soname, path)
 
-			generated.Runtime = append(generated.Runtime, fmt.Sprintf("so:%s", soname))
+			reason := fmt.Sprintf("symlink to %s in %s with SONAME %s", realPath, targetPkg, soname)
+			addRuntime(ctx, generated, fmt.Sprintf("so:%s", soname), path, reason)
 
 			shlibVer, err := determineShlibVersion(ctx, hdl, soname)
 			if err != nil {
 				return err
 			}
 			if shlibVer != "" {
-				generated.Runtime = append(generated.Runtime, fmt.Sprintf("so-ver:%s>=%s", soname, shlibVer))
+				addRuntime(ctx, generated, fmt.Sprintf("so-ver:%s>=%s", soname, shlibVer), path, reason)
+			}
+		}
+	}
+
+	return nil
+}
+
+// expandRunpath returns the directories of DT_RUNPATH or DT_RPATH entries of
+// the ELF object at path, relative to the root of the package.
+func expandRunpath(entries []string, path string) []string {
+	origin := "/" + filepath.Dir(path)
+	expand := strings.NewReplacer("${ORIGIN}", origin, "$ORIGIN", origin, "${LIB}", "lib", "$LIB", "lib")
+
+	var dirs []string
+	for _, entry := range entries {
+		for _, dir := range strings.Split(entry, ":") {
+			dir = expand.Replace(dir)
+
+			// Relative directories are relative to the working directory of
+			// the process, which we can't know.
+			if !filepath.IsAbs(dir) {
+				continue
+			}
+
+			dirs = append(dirs, strings.TrimPrefix(filepath.Clean(dir), "/"))
+		}
+	}
+
+	return dirs
+}
+
+// findVendoredLibrary returns where the package ships lib in one of dirs,
+// unless it is one of the library directories searched by default.
+func findVendoredLibrary(fsys SCAFS, dirs []string, lib string, defaultDirs []string) (string, bool) {
+	for _, dir := range dirs {
+		candidate := filepath.Join(dir, lib)
+		if isInDir(candidate, defaultDirs) {
+			continue
+		}
+
+		if _, err := fsys.Stat(candidate); err == nil {
+			return candidate, true
+		}
+		if _, err := fsys.Readlink(candidate); err == nil {
+			return candidate, true
+		}
+	}
+
+	return "", false
+}
+
+// neededLibraries returns the libraries needed by the ELF object at path,
+// leaving out those the package ships itself in the directories of the
+// object's DT_RUNPATH, or DT_RPATH if it has none.
+func neededLibraries(ctx context.Context, fsys SCAFS, ef *elf.File, path string, defaultDirs []string) ([]string, error) {
+	log := clog.FromContext(ctx)
+
+	libs, err := ef.ImportedLibraries()
+	if err != nil {
+		return nil, err
+	}
+
+	runpath, err := ef.DynString(elf.DT_RUNPATH)
+	if err != nil || len(runpath) == 0 {
+		runpath, _ = ef.DynString(elf.DT_RPATH)
+	}
+	dirs := expandRunpath(runpath, path)
+	if len(dirs) == 0 {
+		return libs, nil
+	}
+
+	return slices.DeleteFunc(libs, func(lib string) bool {
+		where, ok := findVendoredLibrary(fsys, dirs, lib, defaultDirs)
+		if ok {
+			log.Infof("  found vendored lib %s for %s at %s", lib, path, where)
+		}
+		return ok
+	}), nil
+}
+
+// generateNeededLibraryDeps generates so: dependencies for the libraries
+// needed by the ELF object at path.
+func generateNeededLibraryDeps(ctx context.Context, hdl SCAHandle, path string, libs []string, generated *config.Dependencies) error {
+	log := clog.FromContext(ctx)
+
+	for _, lib := range libs {
+		// These are dangling libraries, which must come from the host
+		if isHostProvidedLibrary(lib) {
+			continue
+		}
+		if strings.Contains(lib, ".so.") {
+			log.Infof("  found lib %s for %s", lib, path)
+			addRuntime(ctx, generated, fmt.Sprintf("so:%s", lib), path, "DT_NEEDED "+lib)
+
+			shlibVer, err := determineShlibVersion(ctx, hdl, lib)
+			if err != nil {
+				return err
+			}
+			if shlibVer != "" {
+				addRuntime(ctx, generated, fmt.Sprintf("so-ver:%s>=%s", lib, shlibVer), path, "DT_NEEDED "+lib)
 			}
 		}
 	}
@@ -585,6 +699,11 @@ func generateSharedObjectNameDeps(ctx context.Context, hdl SCAHandle, generated
 
 		basename := filepath.Base(path)
 
+		// Native Node.js addons are handled by generateNodeDeps.
+		if filepath.Ext(basename) == ".node" {
+			return nil
+		}
+
 		// most likely a shell script instead of an ELF, so treat any
 		// error as non-fatal.
 		rawFile, err := fsys.Open(path)
@@ -615,32 +734,17 @@ func generateSharedObjectNameDeps(ctx context.Context, hdl SCAHandle, generated
 			// the dependency.
 			interpName := fmt.Sprintf("so:%s", filepath.Base(interp))
 			interpName = strings.ReplaceAll(interpName, "so:ld-musl", "so:libc.musl")
-			generated.Runtime = append(generated.Runtime, interpName)
+			addRuntime(ctx, generated, interpName, path, "PT_INTERP "+interp)
 		}
 
-		libs, err := ef.ImportedLibraries()
+		libs, err := neededLibraries(ctx, fsys, ef, path, expandedLibDirs)
 		if err != nil {
 			log.Warnf("WTF: ImportedLibraries() returned error: %v", err)
 			return nil
 		}
 
-		for _, lib := range libs {
-			// These are dangling libraries, which must come from the host
-			if isHostProvidedLibrary(lib) {
-				continue
-			}
-			if strings.Contains(lib, ".so.") {
-				log.Infof("  found lib %s for %s", lib, path)
-				generated.Runtime = append(generated.Runtime, fmt.Sprintf("so:%s", lib))
-
-				shlibVer, err := determineShlibVersion(ctx, hdl, lib)
-				if err != nil {
-					return err
-				}
-				if shlibVer != "" {
-					generated.Runtime = append(generated.Runtime, fmt.Sprintf("so-ver:%s>=%s", lib, shlibVer))
-				}
-			}
+		if err := generateNeededLibraryDeps(ctx, hdl, path, libs, generated); err != nil {
+			return err
 		}
 
 		// An executable program should never have a SONAME, but apparently binaries built
@@ -675,11 +779,11 @@ func generateSharedObjectNameDeps(ctx context.Context, hdl SCAHandle, generated
 				libver := sonameLibver(soname)
 
 				if isInDir(path, expandedLibDirs) {
-					generated.Provides = append(generated.Provides, fmt.Sprintf("so:%s=%s", soname, libver))
-					generated.Provides = append(generated.Provides, fmt.Sprintf("so-ver:%s=%s", soname, hdl.Version()))
+					addProvides(ctx, generated, fmt.Sprintf("so:%s=%s", soname, libver), path, "DT_SONAME "+soname)
+					addProvides(ctx, generated, fmt.Sprintf("so-ver:%s=%s", soname, hdl.Version()), path, "DT_SONAME "+soname)
 				} else {
-					generated.Vendored = append(generated.Vendored, fmt.Sprintf("so:%s=%s", soname, libver))
-					generated.Vendored = append(generated.Vendored, fmt.Sprintf("so-ver:%s=%s", soname, hdl.Version()))
+					addVendored(ctx, generated, fmt.Sprintf("so:%s=%s", soname, libver), path, "DT_SONAME "+soname+" outside of library directories")
+					addVendored(ctx, generated, fmt.Sprintf("so-ver:%s=%s", soname, hdl.Version()), path, "DT_SONAME "+soname+" outside of library directories")
 				}
 			}
 		}
@@ -705,8 +809,8 @@ func generateSharedObjectNameDeps(ctx context.Context, hdl SCAHandle, generated
 		}
 		// strong indication of go-fips openssl compiled binary, will dlopen the below at runtime
 		if cgo && fipscrypto {
-			generated.Runtime = append(generated.Runtime, "openssl-config-fipshardened")
-			generated.Runtime = append(generated.Runtime, "so:libcrypto.so.3")
+			addRuntime(ctx, generated, "openssl-config-fipshardened", path, "Go binary built with cgo and FIPS crypto")
+			addRuntime(ctx, generated, "so:libcrypto.so.3", path, "Go binary built with cgo and FIPS crypto")
 		}
 
 		return nil
@@ -714,6 +818,19 @@ func generateSharedObjectNameDeps(ctx context.Context, hdl SCAHandle, generated
 		return err
 	}
 
+	for _, lib := range hdl.Options().Dlopen {
+		log.Infof("  found dlopen'ed lib %s", lib)
+		addRuntime(ctx, generated, fmt.Sprintf("so:%s", lib), "", "dlopen option")
+
+		shlibVer, err := determineShlibVersion(ctx, hdl, lib)
+		if err != nil {
+			return err
+		}
+		if shlibVer != "" {
+			addRuntime(ctx, generated, fmt.Sprintf("so-ver:%s>=%s", lib, shlibVer), "", "dlopen option")
+		}
+	}
+
 	return nil
 }
 
@@ -779,29 +896,29 @@ func generatePkgConfigDeps(ctx context.Context, hdl SCAHandle, generated *config
 
 		if isInDir(path, []string{"usr/local/lib/pkgconfig/", "usr/local/share/pkgconfig/", "usr/lib/pkgconfig/", "usr/lib64/pkgconfig/", "usr/share/pkgconfig/"}) {
 			log.Infof("  found pkg-config %s for %s", pcName, path)
-			generated.Provides = append(generated.Provides, fmt.Sprintf("pc:%s=%s", pcName, hdl.Version()))
+			addProvides(ctx, generated, fmt.Sprintf("pc:%s=%s", pcName, hdl.Version()), path, "pkg-config file")
 
 			if generateRuntimePkgConfigDeps {
 				// TODO(kaniini): Capture version relationships here too.  In practice, this does not matter
 				// so much though for us.
 				for _, dep := range pkg.Requires {
 					log.Infof("  found pkg-config dependency (requires) %s for %s", dep.Identifier, path)
-					generated.Runtime = append(generated.Runtime, fmt.Sprintf("pc:%s", dep.Identifier))
+					addRuntime(ctx, generated, fmt.Sprintf("pc:%s", dep.Identifier), path, "Requires "+dep.Identifier)
 				}
 
 				for _, dep := range pkg.RequiresPrivate {
 					log.Infof("  found pkg-config dependency (requires private) %s for %s", dep.Identifier, path)
-					generated.Runtime = append(generated.Runtime, fmt.Sprintf("pc:%s", dep.Identifier))
+					addRuntime(ctx, generated, fmt.Sprintf("pc:%s", dep.Identifier), path, "Requires.private "+dep.Identifier)
 				}
 
 				for _, dep := range pkg.RequiresInternal {
 					log.Infof("  found pkg-config dependency (requires internal) %s for %s", dep.Identifier, path)
-					generated.Runtime = append(generated.Runtime, fmt.Sprintf("pc:%s", dep.Identifier))
+					addRuntime(ctx, generated, fmt.Sprintf("pc:%s", dep.Identifier), path, "Requires.internal "+dep.Identifier)
 				}
 			}
 		} else {
 			log.Infof("  found vendored pkg-config %s for %s", pcName, path)
-			generated.Vendored = append(generated.Vendored, fmt.Sprintf("pc:%s=%s", pcName, hdl.Version()))
+			addVendored(ctx, generated, fmt.Sprintf("pc:%s=%s", pcName, hdl.Version()), path, "pkg-config file outside of pkg-config directories")
 		}
 
 		return nil
@@ -823,7 +940,7 @@ func generatePythonDeps(ctx context.Context, hdl SCAHandle, generated *config.De
 		return err
 	}
 
-	var pythonModuleVer string
+	var pythonModuleVer, pythonModuleDir string
 	if err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -852,11 +969,16 @@ func generatePythonDeps(ctx context.Context, hdl SCAHandle, generated *config.De
 		// If the X.Y part is not present, then pythonModuleVer will remain an empty string and
 		// no dependency will be generated.
 		pythonModuleVer = basename[6:]
+		pythonModuleDir = path
 		return nil
 	}); err != nil {
 		return err
 	}
 
+	if err := generatePythonDistDeps(ctx, hdl, fsys, generated); err != nil {
+		return err
+	}
+
 	// Nothing to do...
 	if pythonModuleVer == "" {
 		return nil
@@ -871,7 +993,7 @@ func generatePythonDeps(ctx context.Context, hdl SCAHandle, generated *config.De
 	}
 
 	log.Infof("  found python module, generating python-%s-base dependency", pythonModuleVer)
-	generated.Runtime = append(generated.Runtime, fmt.Sprintf("python-%s-base", pythonModuleVer))
+	addRuntime(ctx, generated, fmt.Sprintf("python-%s-base", pythonModuleVer), pythonModuleDir, "python module directory")
 
 	return nil
 }
@@ -919,7 +1041,7 @@ func generateRubyDeps(ctx context.Context, hdl SCAHandle, generated *config.Depe
 	}
 
 	log.Infof("  found ruby gem, generating ruby-%s dependency", rubyGemVer)
-	generated.Runtime = append(generated.Runtime, fmt.Sprintf("ruby-%s", rubyGemVer))
+	addRuntime(ctx, generated, fmt.Sprintf("ruby-%s", rubyGemVer), rubyGemMatches[0], "ruby gems directory")
 
 	return nil
 }
@@ -951,7 +1073,7 @@ func generateDocDeps(ctx context.Context, hdl SCAHandle, generated *config.Depen
 			}
 
 			log.Infof("  found files in /usr/share/man/ in package, generating man-db dependency")
-			generated.Runtime = append(generated.Runtime, "man-db")
+			addRuntime(ctx, generated, "man-db", path, "man page")
 		}
 
 		if isInDir(path, []string{"usr/share/info"}) {
@@ -963,7 +1085,7 @@ func generateDocDeps(ctx context.Context, hdl SCAHandle, generated *config.Depen
 			}
 
 			log.Infof("  found files in /usr/share/info/ in package, generating texinfo dependency")
-			generated.Runtime = append(generated.Runtime, "texinfo")
+			addRuntime(ctx, generated, "texinfo", path, "info page")
 		}
 		return nil
 	}); err != nil {
@@ -1055,7 +1177,9 @@ func generateShbangDeps(ctx context.Context, hdl SCAHandle, generated *config.De
 		return err
 	}
 
+	// The files using each interpreter, and its full path.
 	cmds := map[string]string{}
+	interps := map[string]string{}
 	if err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -1081,6 +1205,7 @@ func generateShbangDeps(ctx context.Context, hdl SCAHandle, generated *config.De
 				log.Warnf("Error reading shbang from %s: %v", path, err)
 			} else if shbang != "" {
 				cmds[filepath.Base(shbang)] = path
+				interps[filepath.Base(shbang)] = shbang
 			}
 			fp.Close()
 		} else {
@@ -1093,7 +1218,7 @@ func generateShbangDeps(ctx context.Context, hdl SCAHandle, generated *config.De
 
 	for base, path := range cmds {
 		log.Infof("Added shbang dep cmd:%s for %s", base, path)
-		generated.Runtime = append(generated.Runtime, "cmd:"+base)
+		addRuntime(ctx, generated, "cmd:"+base, path, "shebang "+interps[base])
 	}
 
 	return nil
@@ -1107,18 +1232,28 @@ func Analyze(ctx context.Context, hdl SCAHandle, generated *config.Dependencies)
 		return err
 	}
 
-	generators := []DependencyGenerator{
-		generateSharedObjectNameDeps,
-		generateCmdProviders,
-		generateDocDeps,
-		generatePkgConfigDeps,
-		generatePythonDeps,
-		generateRubyDeps,
-		generateShbangDeps,
+	generators := []struct {
+		name string
+		gen  DependencyGenerator
+	}{
+		{"shared-objects", generateSharedObjectNameDeps},
+		{"commands", generateCmdProviders},
+		{"docs", generateDocDeps},
+		{"pkg-config", generatePkgConfigDeps},
+		{"python", generatePythonDeps},
+		{"ruby", generateRubyDeps},
+		{"perl", generatePerlDeps},
+		{"nodejs", generateNodeDeps},
+		{"java", generateJavaDeps},
+		{"shbang", generateShbangDeps},
 	}
 
-	for _, gen := range generators {
-		if err := gen(ctx, hdl, generated, extraLibDirs); err != nil {
+	e := explainerFromContext(ctx)
+	for _, g := range generators {
+		if e != nil {
+			e.generator = g.name
+		}
+		if err := g.gen(ctx, hdl, generated, extraLibDirs); err != nil {
 			return err
 		}
 	}
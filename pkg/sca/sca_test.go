@@ -25,12 +25,16 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/fs"
+	"maps"
 	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"chainguard.dev/apko/pkg/apk/apk"
@@ -56,6 +60,10 @@ func (th *testHandle) Version() string {
 	return th.pkg.Version
 }
 
+func (th *testHandle) Arch() string {
+	return th.pkg.Arch
+}
+
 func (th *testHandle) RelativeNames() []string {
 	// TODO: Support subpackages?
 	return []string{th.pkg.Name}
@@ -92,6 +100,80 @@ func (th *testHandle) PkgResolver() *apk.PkgResolver {
 	return nil
 }
 
+// memHandle is an SCAHandle for a package whose contents are given in memory,
+// for tests that don't need a real APK.
+type memHandle struct {
+	name      string
+	version   string
+	arch      string
+	files     fstest.MapFS
+	relatives map[string]fstest.MapFS
+	options   config.PackageOption
+	deps      config.Dependencies
+	installed map[string]string
+	resolver  *apk.PkgResolver
+}
+
+// memFS adds the accessors the SCA engine needs to fstest.MapFS.
+type memFS struct {
+	fstest.MapFS
+}
+
+func (m memFS) Readlink(name string) (string, error) {
+	f, ok := m.MapFS[name]
+	if !ok || f.Mode&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return string(f.Data), nil
+}
+
+func (mh *memHandle) PackageName() string {
+	return mh.name
+}
+
+func (mh *memHandle) Version() string {
+	return mh.version
+}
+
+func (mh *memHandle) Arch() string {
+	return mh.arch
+}
+
+func (mh *memHandle) RelativeNames() []string {
+	return append([]string{mh.name}, slices.Sorted(maps.Keys(mh.relatives))...)
+}
+
+func (mh *memHandle) FilesystemForRelative(pkgName string) (SCAFS, error) {
+	if pkgName == mh.name {
+		return mh.Filesystem()
+	}
+	files, ok := mh.relatives[pkgName]
+	if !ok {
+		return nil, fmt.Errorf("no such relative %q", pkgName)
+	}
+	return memFS{files}, nil
+}
+
+func (mh *memHandle) Filesystem() (SCAFS, error) {
+	return memFS{mh.files}, nil
+}
+
+func (mh *memHandle) Options() config.PackageOption {
+	return mh.options
+}
+
+func (mh *memHandle) BaseDependencies() config.Dependencies {
+	return mh.deps
+}
+
+func (mh *memHandle) InstalledPackages() map[string]string {
+	return mh.installed
+}
+
+func (mh *memHandle) PkgResolver() *apk.PkgResolver {
+	return mh.resolver
+}
+
 // TODO: Loose coupling.
 func handleFromApk(ctx context.Context, t *testing.T, apkfile, melangefile string) *testHandle {
 	t.Helper()
@@ -368,3 +450,59 @@ func TestLdSoConfD(t *testing.T) {
 		t.Errorf("getLdSoConfDLibPaths: expected 'my/lib/test', got '%s'", extraLibPaths[0])
 	}
 }
+
+func TestExpandRunpath(t *testing.T) {
+	got := expandRunpath([]string{"$ORIGIN/../lib:${ORIGIN}/plugins", "/opt/foo/$LIB:relative"}, "opt/foo/bin/foo")
+	want := []string{"opt/foo/lib", "opt/foo/bin/plugins", "opt/foo/lib"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("expandRunpath(): (-want, +got):\n%s", diff)
+	}
+}
+
+func TestFindVendoredLibrary(t *testing.T) {
+	fsys := memFS{fstest.MapFS{
+		"opt/foo/lib/libfoo.so.1":   {},
+		"opt/foo/lib/libbar.so.1":   {Data: []byte("libbar.so.1.2"), Mode: fs.ModeSymlink},
+		"usr/lib/libbaz.so.1":       {},
+		"opt/foo/plugins/libqux.so": {},
+	}}
+	dirs := []string{"opt/foo/lib", "usr/lib"}
+
+	for lib, want := range map[string]string{
+		"libfoo.so.1": "opt/foo/lib/libfoo.so.1",
+		// Dangling symlinks are vendored too, the target is just missing.
+		"libbar.so.1": "opt/foo/lib/libbar.so.1",
+		// Libraries in the default directories are provided as usual.
+		"libbaz.so.1": "",
+		"libqux.so":   "",
+		"libc.so.6":   "",
+	} {
+		got, _ := findVendoredLibrary(fsys, dirs, lib, libDirs)
+		if got != want {
+			t.Errorf("findVendoredLibrary(%q): want %q, got %q", lib, want, got)
+		}
+	}
+}
+
+func TestDlopenDeps(t *testing.T) {
+	ctx := slogtest.Context(t)
+
+	hdl := &memHandle{
+		name:    "foo",
+		version: "1.0-r0",
+		files: fstest.MapFS{
+			"usr/share/foo/README": {},
+		},
+		options: config.PackageOption{Dlopen: []string{"libGL.so.1", "libpulse.so.0"}},
+	}
+
+	got := config.Dependencies{}
+	if err := Analyze(ctx, hdl, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := config.Dependencies{Runtime: []string{"so:libGL.so.1", "so:libpulse.so.0"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Analyze(): (-want, +got):\n%s", diff)
+	}
+}